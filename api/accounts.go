@@ -2,11 +2,14 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/token"
 	"github.com/gunhachi/poke-blackmarket/util"
 	"github.com/lib/pq"
 )
@@ -20,17 +23,21 @@ type createAccountRequest struct {
 
 // responseAccount represent response of handler
 type responseAccount struct {
-	Username  string    `json:"username"`
-	FullName  string    `json:"full_name"`
-	CreatedAt time.Time `json:"created_at"`
+	Username    string    `json:"username"`
+	FullName    string    `json:"full_name"`
+	IsDisabled  bool      `json:"is_disabled"`
+	TotpEnabled bool      `json:"totp_enabled"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // buildAccountResponse build expected response
 func buildAccountResponse(account db.Account) responseAccount {
 	return responseAccount{
-		Username:  account.Username,
-		FullName:  account.FullName,
-		CreatedAt: account.CreatedAt,
+		Username:    account.Username,
+		FullName:    account.FullName,
+		IsDisabled:  account.IsDisabled,
+		TotpEnabled: account.TotpEnabled,
+		CreatedAt:   account.CreatedAt,
 	}
 }
 
@@ -42,7 +49,12 @@ func (server *Server) createAccountLog(ctx *gin.Context) {
 		return
 	}
 
-	hashedPassword, err := util.HashPassword(req.Password)
+	if err := server.passwordPolicy.Validate(req.Password); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := server.hasher.Hash(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
@@ -80,10 +92,19 @@ type loginAccountRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+// responseSession represent the tokens issued for a new session
+type responseSession struct {
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
 // responseLoginAccount represent response for given access such token
 type responseLoginAccount struct {
-	AccessToken string          `json:"access_token"`
-	User        responseAccount `json:"account"`
+	responseSession
+	User responseAccount `json:"account"`
 }
 
 // loginAccount handler to login based on existing data
@@ -94,10 +115,15 @@ func (server *Server) loginAccount(ctx *gin.Context) {
 		return
 	}
 
+	if !server.checkLoginLockout(ctx, req.Username) {
+		return
+	}
+
 	account, err := server.store.GetAccountLog(ctx, req.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			server.checkDummyPassword(req.Password)
+			server.loginFailed(ctx, req.Username, errInvalidCredentials)
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -105,23 +131,235 @@ func (server *Server) loginAccount(ctx *gin.Context) {
 	}
 	err = util.CheckPassword(req.Password, account.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		server.loginFailed(ctx, req.Username, errInvalidCredentials)
 		return
 	}
 
-	accessToken, _, err := server.tokenMaker.CreateToken(
-		account.Username,
-		server.config.AccessTokenDuration,
-	)
+	account, valid := server.rehashPassword(ctx, account, req.Password)
+	if !valid {
+		return
+	}
+
+	if account.IsDisabled {
+		err := errors.New("account is disabled")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	if account.TotpEnabled {
+		server.requireSecondFactor(ctx, account)
+		return
+	}
+
+	server.completeLogin(ctx, account)
+}
+
+// rehashPassword stores a new hash of the password when the current one was made by a weaker hasher,
+// the password change time is kept so existing tokens stay valid
+func (server *Server) rehashPassword(ctx *gin.Context, account db.Account, password string) (db.Account, bool) {
+	if !server.hasher.NeedsRehash(account.HashedPassword) {
+		return account, true
+	}
+
+	hashedPassword, err := server.hasher.Hash(password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	account, err = server.store.UpdateAccountPassword(ctx, db.UpdateAccountPasswordParams{
+		Username:          account.Username,
+		HashedPassword:    hashedPassword,
+		PasswordChangetAt: account.PasswordChangetAt,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+// completeLogin clears the failed attempts of the account and opens a new session for it
+func (server *Server) completeLogin(ctx *gin.Context, account db.Account) {
+	if !server.resetLoginFailures(ctx, account.Username) {
+		return
+	}
+
+	session, valid := server.createSession(ctx, account.Username, 0, "")
+	if !valid {
 		return
 	}
 
 	rsp := responseLoginAccount{
-		AccessToken: accessToken,
-		User:        buildAccountResponse(account),
+		responseSession: session,
+		User:            buildAccountResponse(account),
 	}
 
 	ctx.JSON(http.StatusOK, rsp)
 }
+
+// updatePasswordRequest represent param to change the password of the authenticated account
+type updatePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// updatePassword handler to change password, every session and token issued before the change is rejected afterward,
+// the sessions are blocked by ChangePasswordTx and the tokens are revoked through the revocation store once it commits
+func (server *Server) updatePassword(ctx *gin.Context) {
+	var req updatePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, err := server.store.GetAccountLog(ctx, authPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = util.CheckPassword(req.OldPassword, account.HashedPassword)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if err := server.passwordPolicy.Validate(req.NewPassword); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := server.hasher.Hash(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		Username:       account.Username,
+		HashedPassword: hashedPassword,
+		ChangedAt:      time.Now(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	account = result.Account
+	err = server.revocations.RevokeAccount(ctx, account.Username, account.PasswordChangetAt.Time)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildAccountResponse(account))
+}
+
+// updateAccountDisabledRequest represent param to disable or enable an account
+type updateAccountDisabledRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+}
+
+// disableAccount handler for admin to disable an account, every session and token of it is revoked
+func (server *Server) disableAccount(ctx *gin.Context) {
+	server.updateAccountDisabled(ctx, true)
+}
+
+// enableAccount handler for admin to enable a disabled account
+func (server *Server) enableAccount(ctx *gin.Context) {
+	server.updateAccountDisabled(ctx, false)
+}
+
+func (server *Server) updateAccountDisabled(ctx *gin.Context, disabled bool) {
+	var req updateAccountDisabledRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if disabled && req.Username == authPayload.Username {
+		err := errors.New("cannot disable the authenticated account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.UpdateAccountDisabled(ctx, db.UpdateAccountDisabledParams{
+		Username:   req.Username,
+		IsDisabled: disabled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if disabled {
+		err = server.revokeAccount(ctx, account.Username, time.Now())
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, buildAccountResponse(account))
+}
+
+// createSession issue the access and refresh token of a new session acting as the given user and role
+func (server *Server) createSession(ctx *gin.Context, username string, userID int64, role string) (responseSession, bool) {
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		username,
+		userID,
+		role,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return responseSession{}, false
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateRefreshToken(
+		username,
+		userID,
+		role,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return responseSession{}, false
+	}
+
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return responseSession{}, false
+	}
+
+	rsp := responseSession{
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+	}
+
+	return rsp, true
+}
@@ -2,19 +2,23 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
 	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/token"
 	"github.com/gunhachi/poke-blackmarket/util"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
@@ -63,6 +67,10 @@ func randomAccount(t *testing.T) (user db.Account, password string) {
 func TestCreateAccountAPI(t *testing.T) {
 	account, password := randomAccount(t)
 
+	breachedPath := filepath.Join(t.TempDir(), "breached.txt")
+	err := ioutil.WriteFile(breachedPath, []byte("password123\n"), 0600)
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name          string
 		body          gin.H
@@ -157,6 +165,22 @@ func TestCreateAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "BreachedPassword",
+			body: gin.H{
+				"username":  account.Username,
+				"password":  "password123",
+				"full_name": account.FullName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -170,6 +194,8 @@ func TestCreateAccountAPI(t *testing.T) {
 			tc.buildStubs(store)
 
 			server := newTestServer(t, store)
+			server.passwordPolicy, err = util.NewPasswordPolicy(6, breachedPath)
+			require.NoError(t, err)
 			recorder := httptest.NewRecorder()
 
 			// Marshal body data to JSON
@@ -202,16 +228,58 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
 				store.EXPECT().
 					GetAccountLog(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
-
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Eq(db.DeleteLoginFailureParams{
+						Scope:   loginScopeUsername,
+						Subject: user.Username,
+					})).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "CreateSessionError",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Eq(db.DeleteLoginFailureParams{
+						Scope:   loginScopeUsername,
+						Subject: user.Username,
+					})).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name: "UserNotFound",
 			body: gin.H{
@@ -219,13 +287,123 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
 				store.EXPECT().
 					GetAccountLog(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
+				store.EXPECT().
+					LockLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "DisabledAccount",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				disabled := user
+				disabled.IsDisabled = true
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(disabled, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "RehashPassword",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				bcryptUser := user
+				bcryptHash, err := util.NewBcryptHasher().Hash(password)
+				require.NoError(t, err)
+				bcryptUser.HashedPassword = bcryptHash
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(bcryptUser, nil)
+				store.EXPECT().
+					UpdateAccountPassword(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateAccountPasswordParams) (db.Account, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, user.PasswordChangetAt, arg.PasswordChangetAt)
+						require.False(t, util.NewArgon2idHasher().NeedsRehash(arg.HashedPassword))
+						require.NoError(t, util.CheckPassword(password, arg.HashedPassword))
+						return user, nil
+					})
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MfaRequired",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				enrolled := user
+				enrolled.TotpEnabled = true
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(enrolled, nil)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp responseLoginMfa
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.True(t, rsp.MfaRequired)
+				require.NotEmpty(t, rsp.MfaToken)
 			},
 		},
 		{
@@ -235,15 +413,95 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": "incorrect",
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
+				store.EXPECT().
+					LockLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "LockedOut",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Eq(db.GetLoginLockedUntilParams{
+						Username: user.Username,
+						ClientIp: "192.0.2.1",
+					})).
+					Times(1).
+					Return(time.Now().Add(90*time.Second), nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "90", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "LockoutReached",
+			body: gin.H{
+				"username": user.Username,
+				"password": "incorrect",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
 				store.EXPECT().
 					GetAccountLog(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 5}, nil)
+				store.EXPECT().
+					LockLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "LockoutInternalError",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, sql.ErrConnDone)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name: "InternalError",
 			body: gin.H{
@@ -251,6 +509,10 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
 				store.EXPECT().
 					GetAccountLog(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -298,6 +560,7 @@ func TestLoginUserAPI(t *testing.T) {
 			url := "/account/login"
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			require.NoError(t, err)
+			request.RemoteAddr = "192.0.2.1:1234"
 
 			server.route.ServeHTTP(recorder, request)
 			tc.checkResponse(recorder)
@@ -305,6 +568,311 @@ func TestLoginUserAPI(t *testing.T) {
 	}
 }
 
+func TestUpdatePasswordAPI(t *testing.T) {
+	account, password := randomAccount(t)
+	newPassword := util.RandomString(8)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams) (db.ChangePasswordTxResult, error) {
+						require.Equal(t, account.Username, arg.Username)
+						require.NoError(t, util.CheckPassword(newPassword, arg.HashedPassword))
+						require.WithinDuration(t, time.Now(), arg.ChangedAt, time.Second)
+
+						updated := account
+						updated.HashedPassword = arg.HashedPassword
+						updated.PasswordChangetAt = sql.NullTime{Time: arg.ChangedAt, Valid: true}
+						return db.ChangePasswordTxResult{Account: updated}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "IncorrectOldPassword",
+			body: gin.H{
+				"old_password": "incorrect",
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TooShortNewPassword",
+			body: gin.H{
+				"old_password": password,
+				"new_password": "123",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ChangePasswordTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// Marshal body data to JSON
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/account/password"
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+
+			if recorder.Code == http.StatusOK {
+				// the token issued before the password change must be rejected
+				authorizationHeader := request.Header.Get(authorizationHeaderKey)
+				request, err = http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+
+				recorder = httptest.NewRecorder()
+				server.route.ServeHTTP(recorder, request)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestDisableAccountAPI(t *testing.T) {
+	admin, _ := randomAccount(t)
+	account, _ := randomAccount(t)
+
+	testCases := []struct {
+		name          string
+		url           string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Disable",
+			url:  "/account/disable",
+			body: gin.H{"username": account.Username},
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				disabled := account
+				disabled.IsDisabled = true
+				arg := db.UpdateAccountDisabledParams{
+					Username:   account.Username,
+					IsDisabled: true,
+				}
+				store.EXPECT().
+					UpdateAccountDisabled(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(disabled, nil)
+				store.EXPECT().
+					BlockAccountSessions(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp responseAccount
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.True(t, rsp.IsDisabled)
+			},
+		},
+		{
+			name: "Enable",
+			url:  "/account/enable",
+			body: gin.H{"username": account.Username},
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateAccountDisabledParams{
+					Username:   account.Username,
+					IsDisabled: false,
+				}
+				store.EXPECT().
+					UpdateAccountDisabled(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					BlockAccountSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			url:  "/account/disable",
+			body: gin.H{"username": account.Username},
+			role: "LEAD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountDisabled(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "DisableSelf",
+			url:  "/account/disable",
+			body: gin.H{"username": admin.Username},
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountDisabled(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			url:  "/account/disable",
+			body: gin.H{"username": account.Username},
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountDisabled(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					BlockAccountSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// Marshal body data to JSON
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, 1, tc.role, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, user db.Account) {
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/token"
+	"github.com/gunhachi/poke-blackmarket/util"
+)
+
+// apiKeyKind is the kind of the payload built for an api key, it cannot reach the session only routes
+const apiKeyKind = "api_key"
+
+// Different types of error returned by the api key authorization
+var (
+	errApiKeyRevoked     = errors.New("api key has been revoked")
+	errApiKeyExpired     = errors.New("api key has expired")
+	errApiKeyNotAccepted = errors.New("route only accepts the access token of a session, not an api key")
+)
+
+// createApiKeyRequest represent param to create an api key acting as one of the account users
+type createApiKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	UserID    int64      `json:"user_id" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// apiKeyResponse represent an api key without its secret
+type apiKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	UserID     int64      `json:"user_id"`
+	Role       string     `json:"role"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// createApiKeyResponse represent a new api key, the key is only shown once
+type createApiKeyResponse struct {
+	Key    string         `json:"key"`
+	ApiKey apiKeyResponse `json:"api_key"`
+}
+
+// buildApiKeyResponse build expected response
+func buildApiKeyResponse(apiKey db.ApiKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		UserID:     apiKey.UserID,
+		Role:       apiKey.Role,
+		ExpiresAt:  nullTime(apiKey.ExpiresAt),
+		LastUsedAt: nullTime(apiKey.LastUsedAt),
+		RevokedAt:  nullTime(apiKey.RevokedAt),
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// createApiKey handler to create a long lived api key carrying the role of the given user
+func (server *Server) createApiKey(ctx *gin.Context) {
+	var req createApiKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserAccount(ctx, req.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if user.UserName != authPayload.Username {
+		err := errors.New("user dont belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	key, prefix, err := util.GenerateApiKey()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateApiKeyParams{
+		Username:  authPayload.Username,
+		Name:      req.Name,
+		Prefix:    prefix,
+		HashedKey: util.HashSecret(key),
+		UserID:    user.ID,
+		Role:      user.UserRole,
+	}
+	if req.ExpiresAt != nil {
+		arg.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	apiKey, err := server.store.CreateApiKey(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := createApiKeyResponse{
+		Key:    key,
+		ApiKey: buildApiKeyResponse(apiKey),
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// listApiKeysRequest represent parameter to list the api keys of the account
+type listApiKeysRequest struct {
+	pageRequest
+}
+
+// listApiKeys handler to list the api keys of the authenticated account
+func (server *Server) listApiKeys(ctx *gin.Context) {
+	var req listApiKeysRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, valid := bindCursor(ctx, req.pageRequest, "", false)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListApiKeysParams{
+		Username: authPayload.Username,
+		AfterID:  sql.NullInt64{Int64: cursor.ID, Valid: cursor.ID != 0},
+	}
+	arg.Limit, arg.Offset = req.query()
+
+	apiKeys, err := server.store.ListApiKeys(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]apiKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		rsp = append(rsp, buildApiKeyResponse(apiKey))
+	}
+
+	if req.offsetPaged() {
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	page := listResponse{Data: rsp}
+	if req.hasNextPage(len(rsp)) {
+		rsp = rsp[:req.pageLimit()]
+		page.Data = rsp
+		page.NextCursor = pageCursor{ID: rsp[len(rsp)-1].ID}.encode()
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// revokeApiKeyRequest represent id of the api key to revoke
+type revokeApiKeyRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// revokeApiKey handler to revoke an api key of the authenticated account
+func (server *Server) revokeApiKey(ctx *gin.Context) {
+	var req revokeApiKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	apiKey, err := server.store.RevokeApiKey(ctx, db.RevokeApiKeyParams{
+		ID:       req.ID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildApiKeyResponse(apiKey))
+}
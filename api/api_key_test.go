@@ -0,0 +1,426 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRandomApiKey create api key data and its plain key
+func mockRandomApiKey(t *testing.T, user db.User) (db.ApiKey, string) {
+	key, prefix, err := util.GenerateApiKey()
+	require.NoError(t, err)
+
+	apiKey := db.ApiKey{
+		ID:        util.RandomInt(1, 200),
+		Username:  user.UserName,
+		Name:      util.RandomString(8),
+		Prefix:    prefix,
+		HashedKey: util.HashSecret(key),
+		UserID:    user.ID,
+		Role:      user.UserRole,
+		CreatedAt: time.Now(),
+	}
+	return apiKey, key
+}
+
+func TestCreateApiKeyAPI(t *testing.T) {
+	account, _ := randomAccount(t)
+	user := mockRandomUser(account.Username)
+	user.UserName = account.Username
+	user.UserRole = "GRUNT"
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "restock-bot", "user_id": user.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateApiKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateApiKeyParams) (db.ApiKey, error) {
+						require.Equal(t, account.Username, arg.Username)
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, user.UserRole, arg.Role)
+						require.False(t, arg.ExpiresAt.Valid)
+						return db.ApiKey{
+							ID:        1,
+							Username:  arg.Username,
+							Name:      arg.Name,
+							Prefix:    arg.Prefix,
+							HashedKey: arg.HashedKey,
+							UserID:    arg.UserID,
+							Role:      arg.Role,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "hashed_key")
+
+				var rsp createApiKeyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+
+				prefix, err := util.ApiKeyPrefix(rsp.Key)
+				require.NoError(t, err)
+				require.Equal(t, prefix, rsp.ApiKey.Prefix)
+				require.Equal(t, "GRUNT", rsp.ApiKey.Role)
+				require.Nil(t, rsp.ApiKey.ExpiresAt)
+			},
+		},
+		{
+			name: "WithExpiry",
+			body: gin.H{"name": "report-job", "user_id": user.ID, "expires_at": time.Now().Add(time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateApiKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateApiKeyParams) (db.ApiKey, error) {
+						require.True(t, arg.ExpiresAt.Valid)
+						return db.ApiKey{ID: 1, ExpiresAt: arg.ExpiresAt}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredAlready",
+			body: gin.H{"name": "report-job", "user_id": user.ID, "expires_at": time.Now().Add(-time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateApiKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OtherAccountUser",
+			body: gin.H{"name": "restock-bot", "user_id": user.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := user
+				other.UserName = util.RandomUser()
+				store.EXPECT().
+					GetUserAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					CreateApiKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: gin.H{"name": "restock-bot", "user_id": user.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserAccount(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "MissingName",
+			body: gin.H{"user_id": user.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// Marshal body data to JSON
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/account/api-keys"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListApiKeysAPI(t *testing.T) {
+	account, _ := randomAccount(t)
+	user := mockRandomUser(account.Username)
+	user.UserName = account.Username
+
+	n := 5
+	apiKeys := make([]db.ApiKey, n)
+	for i := 0; i < n; i++ {
+		apiKeys[i], _ = mockRandomApiKey(t, user)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: fmt.Sprintf("page_id=1&page_size=%d", n),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListApiKeysParams{
+					Username: account.Username,
+					Limit:    int32(n),
+					Offset:   0,
+				}
+				store.EXPECT().
+					ListApiKeys(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(apiKeys, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "hashed_key")
+
+				var rsp []apiKeyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp, n)
+				for i := range rsp {
+					require.Equal(t, apiKeys[i].Prefix, rsp[i].Prefix)
+				}
+			},
+		},
+		{
+			name:  "CursorPage",
+			query: "limit=2",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListApiKeysParams{
+					Username: account.Username,
+					Limit:    3,
+				}
+				store.EXPECT().
+					ListApiKeys(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(apiKeys[:3], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page struct {
+					Data       []apiKeyResponse `json:"data"`
+					NextCursor string           `json:"next_cursor"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.Len(t, page.Data, 2)
+				require.Equal(t, pageCursor{ID: apiKeys[1].ID}, decodeTestCursor(t, page.NextCursor))
+			},
+		},
+		{
+			name:  "NextCursorPage",
+			query: "after=" + pageCursor{ID: 9}.encode(),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListApiKeysParams{
+					Username: account.Username,
+					AfterID:  sql.NullInt64{Int64: 9, Valid: true},
+					Limit:    defaultPageLimit + 1,
+				}
+				store.EXPECT().
+					ListApiKeys(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(apiKeys, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.Len(t, page["data"], n)
+				require.Equal(t, "", page["next_cursor"])
+			},
+		},
+		{
+			name:  "InternalError",
+			query: fmt.Sprintf("page_id=1&page_size=%d", n),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListApiKeys(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPageID",
+			query: fmt.Sprintf("page_id=-1&page_size=%d", n),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListApiKeys(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/account/api-keys?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRevokeApiKeyAPI(t *testing.T) {
+	account, _ := randomAccount(t)
+	user := mockRandomUser(account.Username)
+	user.UserName = account.Username
+	apiKey, _ := mockRandomApiKey(t, user)
+
+	testCases := []struct {
+		name          string
+		ID            int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			ID:   apiKey.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				revoked := apiKey
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				arg := db.RevokeApiKeyParams{
+					ID:       apiKey.ID,
+					Username: account.Username,
+				}
+				store.EXPECT().
+					RevokeApiKey(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(revoked, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp apiKeyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotNil(t, rsp.RevokedAt)
+			},
+		},
+		{
+			name: "NotFound",
+			ID:   apiKey.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeApiKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidID",
+			ID:   0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeApiKey(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/account/api-keys/%d", tc.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
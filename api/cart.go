@@ -0,0 +1,212 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/lib/pq"
+)
+
+// selectedUserRequest represent the optional user id of the body, the user selected on the token is used when empty
+type selectedUserRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+// bindSelectedUser binds the optional user id of the body and check it against the token
+func bindSelectedUser(ctx *gin.Context) (int64, bool) {
+	var req selectedUserRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return 0, false
+		}
+	}
+
+	authPayload, valid := authorizedUser(ctx, req.UserID)
+	if !valid {
+		return 0, false
+	}
+
+	return authPayload.UserID, true
+}
+
+// getCart handler to list the items on the cart of the selected user
+func (server *Server) getCart(ctx *gin.Context) {
+	authPayload, valid := authorizedUser(ctx, 0)
+	if !valid {
+		return
+	}
+
+	items, err := server.store.ListCartItems(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+// addCartItemRequest represent request payload for putting a product on the cart
+type addCartItemRequest struct {
+	UserID    int64 `json:"user_id"`
+	ProductID int64 `json:"product_id" binding:"required,min=1"`
+	Quantity  int32 `json:"quantity" binding:"required,min=1"`
+}
+
+// addCartItem handler to put a product on the cart, the quantity is added up when the product is already there
+func (server *Server) addCartItem(ctx *gin.Context) {
+	var req addCartItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload, valid := authorizedUser(ctx, req.UserID)
+	if !valid {
+		return
+	}
+
+	item, err := server.store.AddCartItem(ctx, db.AddCartItemParams{
+		UserID:    authPayload.UserID,
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+// cartItemUriRequest represent id of the cart item for binding parameter
+type cartItemUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// updateCartItemRequest represent request payload for changing the quantity of a cart item
+type updateCartItemRequest struct {
+	UserID   int64 `json:"user_id"`
+	Quantity int32 `json:"quantity" binding:"required,min=1"`
+}
+
+// updateCartItem handler to change the quantity of an item on the cart
+func (server *Server) updateCartItem(ctx *gin.Context) {
+	var uri cartItemUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateCartItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload, valid := authorizedUser(ctx, req.UserID)
+	if !valid {
+		return
+	}
+
+	item, err := server.store.UpdateCartItem(ctx, db.UpdateCartItemParams{
+		ID:       uri.ID,
+		UserID:   authPayload.UserID,
+		Quantity: req.Quantity,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+// removeCartItem handler to take an item off the cart
+func (server *Server) removeCartItem(ctx *gin.Context) {
+	var uri cartItemUriRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	userID, valid := bindSelectedUser(ctx)
+	if !valid {
+		return
+	}
+
+	item, err := server.store.DeleteCartItem(ctx, db.DeleteCartItemParams{
+		ID:     uri.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+// checkoutCartRequest represent the optional body of the checkout
+type checkoutCartRequest struct {
+	UserID        int64  `json:"user_id"`
+	PromotionCode string `json:"promotion_code" binding:"omitempty,alphanum,max=32"`
+}
+
+// checkoutCart handler to turn the whole cart into one order, every line is priced and its stock deducted in one transaction
+func (server *Server) checkoutCart(ctx *gin.Context) {
+	var req checkoutCartRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	authPayload, valid := authorizedUser(ctx, req.UserID)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.CheckoutTx(ctx, db.CheckoutTxParams{
+		UserID:        authPayload.UserID,
+		PromotionCode: strings.ToUpper(req.PromotionCode),
+	})
+	if err != nil {
+		if status := promotionErrorStatus(err); status != 0 {
+			ctx.JSON(status, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrEmptyCart) || errors.Is(err, db.ErrMixedCurrency) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrInsufficientStock) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,372 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCartAPI(t *testing.T) {
+	item := mockRandomCartItem()
+	order := mockRandomOrder()
+	order.UserID = item.UserID
+	order.ProductID = sql.NullInt64{}
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		body          gin.H
+		userID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "GetCart",
+			method: http.MethodGet,
+			path:   "/cart",
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListCartItems(gomock.Any(), gomock.Eq(item.UserID)).
+					Times(1).
+					Return([]db.CartItem{item}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotItems []db.CartItem
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotItems)
+				require.NoError(t, err)
+				require.Equal(t, []db.CartItem{item}, gotItems)
+			},
+		},
+		{
+			name:   "AddItem",
+			method: http.MethodPost,
+			path:   "/cart/items",
+			body:   gin.H{"product_id": item.ProductID, "quantity": item.Quantity},
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AddCartItem(gomock.Any(), gomock.Eq(db.AddCartItemParams{
+						UserID:    item.UserID,
+						ProductID: item.ProductID,
+						Quantity:  item.Quantity,
+					})).
+					Times(1).
+					Return(item, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AddItemUnknownProduct",
+			method: http.MethodPost,
+			path:   "/cart/items",
+			body:   gin.H{"product_id": item.ProductID, "quantity": item.Quantity},
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AddCartItem(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CartItem{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "AddItemInvalidQuantity",
+			method: http.MethodPost,
+			path:   "/cart/items",
+			body:   gin.H{"product_id": item.ProductID, "quantity": -1},
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AddCartItem(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "AddItemOtherUser",
+			method: http.MethodPost,
+			path:   "/cart/items",
+			body:   gin.H{"user_id": item.UserID + 1, "product_id": item.ProductID, "quantity": item.Quantity},
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AddCartItem(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "UpdateItem",
+			method: http.MethodPut,
+			path:   fmt.Sprintf("/cart/items/%d", item.ID),
+			body:   gin.H{"quantity": 3},
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCartItem(gomock.Any(), gomock.Eq(db.UpdateCartItemParams{
+						ID:       item.ID,
+						UserID:   item.UserID,
+						Quantity: 3,
+					})).
+					Times(1).
+					Return(item, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "UpdateItemNotFound",
+			method: http.MethodPut,
+			path:   fmt.Sprintf("/cart/items/%d", item.ID),
+			body:   gin.H{"quantity": 3},
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCartItem(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CartItem{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "RemoveItem",
+			method: http.MethodDelete,
+			path:   fmt.Sprintf("/cart/items/%d", item.ID),
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCartItem(gomock.Any(), gomock.Eq(db.DeleteCartItemParams{
+						ID:     item.ID,
+						UserID: item.UserID,
+					})).
+					Times(1).
+					Return(item, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Checkout",
+			method: http.MethodPost,
+			path:   "/cart/checkout",
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Eq(db.CheckoutTxParams{UserID: item.UserID})).
+					Times(1).
+					Return(db.CheckoutTxResult{Order: order, Items: []db.OrderItem{{OrderID: order.ID}}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotResult db.CheckoutTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotResult)
+				require.NoError(t, err)
+				require.Equal(t, order.ID, gotResult.Order.ID)
+				require.Len(t, gotResult.Items, 1)
+			},
+		},
+		{
+			name:   "CheckoutEmptyCart",
+			method: http.MethodPost,
+			path:   "/cart/checkout",
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CheckoutTxResult{}, db.ErrEmptyCart)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "CheckoutMixedCurrency",
+			method: http.MethodPost,
+			path:   "/cart/checkout",
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CheckoutTxResult{}, fmt.Errorf("%w: USD and EUR", db.ErrMixedCurrency))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "CheckoutInsufficientStock",
+			method: http.MethodPost,
+			path:   "/cart/checkout",
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CheckoutTxResult{}, db.ErrInsufficientStock)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "CheckoutInternalError",
+			method: http.MethodPost,
+			path:   "/cart/checkout",
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CheckoutTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "CheckoutNoUserSelected",
+			method: http.MethodPost,
+			path:   "/cart/checkout",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "ListOrderItems",
+			method: http.MethodGet,
+			path:   fmt.Sprintf("/order/%d/items", order.ID),
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPokemonOrderData(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					ListOrderItems(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return([]db.OrderItem{{OrderID: order.ID}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ListOrderItemsOtherUser",
+			method: http.MethodGet,
+			path:   fmt.Sprintf("/order/%d/items", order.ID),
+			userID: item.UserID + 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPokemonOrderData(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					ListOrderItems(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "ListOrderItemsNotFound",
+			method: http.MethodGet,
+			path:   fmt.Sprintf("/order/%d/items", order.ID),
+			userID: item.UserID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPokemonOrderData(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PokeOrder{}, sql.ErrNoRows)
+				store.EXPECT().
+					ListOrderItems(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body *bytes.Reader
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			} else {
+				body = bytes.NewReader(nil)
+			}
+
+			request, err := http.NewRequest(tc.method, tc.path, body)
+			require.NoError(t, err)
+
+			if tc.userID != 0 {
+				addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), tc.userID, "GRUNT", time.Minute)
+			} else {
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), time.Minute)
+			}
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// mockRandomCartItem create random cart item data
+func mockRandomCartItem() db.CartItem {
+	return db.CartItem{
+		ID:        util.RandomInt(1, 200),
+		UserID:    util.RandomInt(1, 300),
+		ProductID: util.RandomInt(1, 300),
+		Quantity:  int32(util.RandomInt(1, 10)),
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/token"
+	"github.com/gunhachi/poke-blackmarket/util"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+var (
+	errIdempotencyKeyTooLong    = fmt.Errorf("idempotency key is longer than %d characters", maxIdempotencyKeyLength)
+	errIdempotencyKeyReused     = errors.New("idempotency key was already used with another request")
+	errIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still running")
+)
+
+// idempotencyWriter keeps a copy of the response so it can be replayed
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (writer *idempotencyWriter) Write(data []byte) (int, error) {
+	writer.body.Write(data)
+	return writer.ResponseWriter.Write(data)
+}
+
+func (writer *idempotencyWriter) WriteString(data string) (int, error) {
+	writer.body.WriteString(data)
+	return writer.ResponseWriter.WriteString(data)
+}
+
+// idempotencyRequestHash identifies a request by its method, path and body
+func idempotencyRequestHash(method string, path string, body []byte) string {
+	return util.HashSecret(method + " " + path + "\n" + string(body))
+}
+
+// idempotencyMiddleware replays the stored response of a request sent again with the same Idempotency-Key
+// within the ttl, the key is scoped to the account and reusing it with another request answers 422.
+// Requests without the header run as usual, server errors are not stored so the request can be retried
+func idempotencyMiddleware(store db.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(errIdempotencyKeyTooLong))
+			return
+		}
+
+		body, err := ioutil.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		requestHash := idempotencyRequestHash(ctx.Request.Method, ctx.Request.URL.Path, body)
+
+		_, err = store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			Username:       authPayload.Username,
+			IdempotencyKey: key,
+			RequestHash:    requestHash,
+			ExpiredBefore:  time.Now().Add(-ttl),
+		})
+		if err == sql.ErrNoRows {
+			replayIdempotentResponse(ctx, store, authPayload.Username, key, requestHash)
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			_ = store.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{
+				Username:       authPayload.Username,
+				IdempotencyKey: key,
+			})
+			return
+		}
+
+		err = store.SaveIdempotencyResponse(ctx, db.SaveIdempotencyResponseParams{
+			Username:       authPayload.Username,
+			IdempotencyKey: key,
+			ResponseStatus: int32(writer.Status()),
+			ResponseBody:   writer.body.Bytes(),
+		})
+		if err != nil {
+			// the key would stay in progress, the client is able to retry it instead
+			_ = store.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{
+				Username:       authPayload.Username,
+				IdempotencyKey: key,
+			})
+		}
+	}
+}
+
+// replayIdempotentResponse answers with the response stored for the key when it was used for the same request
+func replayIdempotentResponse(ctx *gin.Context, store db.Store, username string, key string, requestHash string) {
+	record, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username:       username,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse(errIdempotencyKeyReused))
+		return
+	}
+
+	if record.ResponseStatus == 0 {
+		ctx.AbortWithStatusJSON(http.StatusConflict, errorResponse(errIdempotencyKeyInProgress))
+		return
+	}
+
+	ctx.Header(idempotencyReplayedHeader, "true")
+	ctx.Data(int(record.ResponseStatus), "application/json; charset=utf-8", record.ResponseBody)
+	ctx.Abort()
+}
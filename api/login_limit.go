@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/util"
+)
+
+const (
+	loginScopeUsername = "username"
+	loginScopeIP       = "ip"
+
+	// maxLoginLockout caps the exponential backoff applied to repeated failures
+	maxLoginLockout = 24 * time.Hour
+)
+
+var (
+	errInvalidCredentials = errors.New("invalid username or password")
+	errLoginLocked        = errors.New("too many failed login attempts, try again later")
+)
+
+// loginLockoutDuration returns how long logins stay locked after failedCount consecutive failures.
+// The first lockout lasts base once maxAttempts is reached and doubles with every further failure.
+func loginLockoutDuration(failedCount int32, maxAttempts int, base time.Duration) time.Duration {
+	if maxAttempts <= 0 || int(failedCount) < maxAttempts {
+		return 0
+	}
+
+	duration := base
+	for i := maxAttempts; i < int(failedCount) && duration < maxLoginLockout; i++ {
+		duration *= 2
+	}
+	if duration > maxLoginLockout {
+		duration = maxLoginLockout
+	}
+	return duration
+}
+
+// checkLoginLockout rejects the request with 429 while the username or client ip is locked
+func (server *Server) checkLoginLockout(ctx *gin.Context, username string) bool {
+	lockedUntil, err := server.store.GetLoginLockedUntil(ctx, db.GetLoginLockedUntilParams{
+		Username: username,
+		ClientIp: ctx.ClientIP(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	retryAfter := time.Until(lockedUntil)
+	if retryAfter > 0 {
+		seconds := int64((retryAfter + time.Second - 1) / time.Second)
+		ctx.Header("Retry-After", strconv.FormatInt(seconds, 10))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errLoginLocked))
+		return false
+	}
+
+	return true
+}
+
+// loginFailed records a failed attempt for the username and client ip, locking them out
+// once the configured number of attempts is exceeded, and responds with 401
+func (server *Server) loginFailed(ctx *gin.Context, username string, loginErr error) {
+	subjects := map[string]string{
+		loginScopeUsername: username,
+		loginScopeIP:       ctx.ClientIP(),
+	}
+
+	for scope, subject := range subjects {
+		failure, err := server.store.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+			Scope:   scope,
+			Subject: subject,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		lockout := loginLockoutDuration(failure.FailedCount, server.config.LoginMaxAttempts, server.config.LoginLockoutDuration)
+		if lockout == 0 {
+			continue
+		}
+
+		err = server.store.LockLoginFailure(ctx, db.LockLoginFailureParams{
+			Scope:   scope,
+			Subject: subject,
+			LockedUntil: sql.NullTime{
+				Time:  time.Now().Add(lockout),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusUnauthorized, errorResponse(loginErr))
+}
+
+// resetLoginFailures clears the failure counter of a username after a successful login.
+// The ip counter is left alone so one valid account cannot be used to reset it.
+func (server *Server) resetLoginFailures(ctx *gin.Context, username string) bool {
+	err := server.store.DeleteLoginFailure(ctx, db.DeleteLoginFailureParams{
+		Scope:   loginScopeUsername,
+		Subject: username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// checkDummyPassword spends the same hashing work as a real password check
+func (server *Server) checkDummyPassword(password string) {
+	server.dummyPasswordOnce.Do(func() {
+		hashed, err := server.hasher.Hash(util.RandomString(16))
+		if err != nil {
+			panic(fmt.Sprintf("cannot hash dummy password: %v", err))
+		}
+		server.dummyPassword = hashed
+	})
+	_ = util.CheckPassword(password, server.dummyPassword)
+}
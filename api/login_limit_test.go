@@ -0,0 +1,32 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginLockoutDuration(t *testing.T) {
+	testCases := []struct {
+		name        string
+		failedCount int32
+		maxAttempts int
+		expected    time.Duration
+	}{
+		{name: "BelowLimit", failedCount: 4, maxAttempts: 5, expected: 0},
+		{name: "LimitReached", failedCount: 5, maxAttempts: 5, expected: time.Minute},
+		{name: "Doubles", failedCount: 7, maxAttempts: 5, expected: 4 * time.Minute},
+		{name: "Capped", failedCount: 100, maxAttempts: 5, expected: maxLoginLockout},
+		{name: "Disabled", failedCount: 100, maxAttempts: 0, expected: 0},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			duration := loginLockoutDuration(tc.failedCount, tc.maxAttempts, time.Minute)
+			require.Equal(t, tc.expected, duration)
+		})
+	}
+}
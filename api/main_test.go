@@ -7,17 +7,25 @@ import (
 
 	"github.com/gin-gonic/gin"
 	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/token"
 	"github.com/gunhachi/poke-blackmarket/util"
 	"github.com/stretchr/testify/require"
 )
 
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymKey:         util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymKey:           util.RandomString(32),
+		AccessTokenDuration:   time.Minute,
+		RefreshTokenDuration:  time.Hour,
+		LoginMaxAttempts:      5,
+		LoginLockoutDuration:  time.Minute,
+		PasswordMinLength:     6,
+		PasswordResetDuration: time.Minute,
+		ReservationDuration:   time.Minute,
+		IdempotencyKeyTTL:     time.Hour,
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, token.NewMemoryRevocationStore())
 	require.NoError(t, err)
 
 	return server
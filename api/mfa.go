@@ -0,0 +1,325 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/token"
+	"github.com/gunhachi/poke-blackmarket/util"
+)
+
+const (
+	// mfaTokenDuration bounds the mfa token issued between the password and the TOTP code,
+	// the token is of its own kind and only accepted by verifyLoginTotp
+	mfaTokenDuration   = 5 * time.Minute
+	totpIssuer         = "poke-blackmarket"
+	recoveryCodesCount = 10
+)
+
+var (
+	errMfaPending      = errors.New("two-factor authentication is not completed")
+	errInvalidMfaToken = errors.New("invalid two-factor authentication token")
+	errInvalidTotpCode = errors.New("invalid two-factor authentication code")
+	errTotpEnabled     = errors.New("two-factor authentication is already enabled")
+	errTotpNotEnrolled = errors.New("two-factor authentication is not enrolled")
+	errTotpNotEnabled  = errors.New("two-factor authentication is not enabled")
+	errMfaNotRequired  = errors.New("account does not require two-factor authentication")
+)
+
+// responseLoginMfa represent response of a login waiting for the second factor
+type responseLoginMfa struct {
+	MfaRequired       bool      `json:"mfa_required"`
+	MfaToken          string    `json:"mfa_token"`
+	MfaTokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+// requireSecondFactor issues the token to be exchanged with a TOTP code on /account/login/mfa
+func (server *Server) requireSecondFactor(ctx *gin.Context, account db.Account) {
+	mfaToken, mfaPayload, err := server.tokenMaker.CreateMfaToken(account.Username, mfaTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := responseLoginMfa{
+		MfaRequired:       true,
+		MfaToken:          mfaToken,
+		MfaTokenExpiresAt: mfaPayload.ExpiredAt,
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// verifyLoginTotpRequest represent param to finish a login with the second factor
+type verifyLoginTotpRequest struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// verifyLoginTotp handler to exchange the mfa token and a TOTP or recovery code for a new session
+func (server *Server) verifyLoginTotp(ctx *gin.Context) {
+	var req verifyLoginTotpRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	mfaPayload, err := server.tokenMaker.VerifyToken(req.MfaToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if mfaPayload.Kind != token.MfaToken {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidMfaToken))
+		return
+	}
+
+	revoked, err := server.revocations.IsRevoked(ctx, mfaPayload.ID, mfaPayload.Username, mfaPayload.IssuedAt)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if revoked {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(token.ErrRevokedToken))
+		return
+	}
+
+	if !server.checkLoginLockout(ctx, mfaPayload.Username) {
+		return
+	}
+
+	account, err := server.store.GetAccountLog(ctx, mfaPayload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidMfaToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !account.TotpEnabled {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errMfaNotRequired))
+		return
+	}
+
+	valid, err := server.verifySecondFactor(ctx, account, req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !valid {
+		server.loginFailed(ctx, account.Username, errInvalidTotpCode)
+		return
+	}
+
+	if account.IsDisabled {
+		err := errors.New("account is disabled")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	// the mfa token is single use
+	err = server.revocations.RevokeToken(ctx, mfaPayload.ID, mfaPayload.Username, mfaPayload.ExpiredAt)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.completeLogin(ctx, account)
+}
+
+// verifySecondFactor checks the TOTP code of the account, or consumes one of its recovery codes.
+// Both are used by a single conditional update, so concurrent logins cannot both use the same code
+// and a TOTP code is not accepted again within the clock skew
+func (server *Server) verifySecondFactor(ctx *gin.Context, account db.Account, code string) (bool, error) {
+	if step, valid := util.TotpStep(account.TotpSecret, code, time.Now()); valid {
+		_, err := server.store.UseAccountTotpStep(ctx, db.UseAccountTotpStepParams{
+			Step:     step,
+			Username: account.Username,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	_, err := server.store.UseAccountRecoveryCode(ctx, db.UseAccountRecoveryCodeParams{
+		RecoveryCode: util.HashRecoveryCode(code),
+		Username:     account.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// enrollTotpResponse represent the secret to be added into an authenticator app
+type enrollTotpResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+}
+
+// enrollTotp handler to generate a new TOTP secret, it is not required on login until confirmed
+func (server *Server) enrollTotp(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, valid := server.authenticatedAccount(ctx, authPayload.Username)
+	if !valid {
+		return
+	}
+
+	if account.TotpEnabled {
+		ctx.JSON(http.StatusForbidden, errorResponse(errTotpEnabled))
+		return
+	}
+
+	secret, err := util.GenerateTotpSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.UpdateAccountTotp(ctx, db.UpdateAccountTotpParams{
+		Username:      account.Username,
+		TotpSecret:    secret,
+		TotpEnabled:   false,
+		RecoveryCodes: []string{},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := enrollTotpResponse{
+		Secret:     secret,
+		OtpauthUri: util.TotpUri(totpIssuer, account.Username, secret),
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// totpCodeRequest represent a TOTP code, or a recovery code where accepted
+type totpCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// confirmTotpResponse represent the recovery codes, only shown once
+type confirmTotpResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTotp handler to enable the enrolled TOTP secret after checking a first code
+func (server *Server) confirmTotp(ctx *gin.Context) {
+	var req totpCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, valid := server.authenticatedAccount(ctx, authPayload.Username)
+	if !valid {
+		return
+	}
+
+	if account.TotpEnabled {
+		ctx.JSON(http.StatusForbidden, errorResponse(errTotpEnabled))
+		return
+	}
+	if account.TotpSecret == "" {
+		ctx.JSON(http.StatusForbidden, errorResponse(errTotpNotEnrolled))
+		return
+	}
+	if !util.ValidateTotp(account.TotpSecret, req.Code, time.Now()) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTotpCode))
+		return
+	}
+
+	recoveryCodes, err := util.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hashedCodes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashedCodes[i] = util.HashRecoveryCode(code)
+	}
+
+	_, err = server.store.UpdateAccountTotp(ctx, db.UpdateAccountTotpParams{
+		Username:      account.Username,
+		TotpSecret:    account.TotpSecret,
+		TotpEnabled:   true,
+		RecoveryCodes: hashedCodes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTotpResponse{RecoveryCodes: recoveryCodes})
+}
+
+// disableTotp handler to turn off two-factor authentication with a TOTP or recovery code
+func (server *Server) disableTotp(ctx *gin.Context) {
+	var req totpCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, valid := server.authenticatedAccount(ctx, authPayload.Username)
+	if !valid {
+		return
+	}
+
+	if !account.TotpEnabled {
+		ctx.JSON(http.StatusForbidden, errorResponse(errTotpNotEnabled))
+		return
+	}
+
+	valid, err := server.verifySecondFactor(ctx, account, req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !valid {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTotpCode))
+		return
+	}
+
+	account, err = server.store.UpdateAccountTotp(ctx, db.UpdateAccountTotpParams{
+		Username:      account.Username,
+		TotpSecret:    "",
+		TotpEnabled:   false,
+		RecoveryCodes: []string{},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildAccountResponse(account))
+}
+
+// authenticatedAccount returns the account of the token, writing the error response when it cannot be read
+func (server *Server) authenticatedAccount(ctx *gin.Context, username string) (db.Account, bool) {
+	account, err := server.store.GetAccountLog(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Account{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Account{}, false
+	}
+	return account, true
+}
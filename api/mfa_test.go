@@ -0,0 +1,601 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/token"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTotpAccount(t *testing.T) (account db.Account, recoveryCode string) {
+	account, _ = randomAccount(t)
+
+	secret, err := util.GenerateTotpSecret()
+	require.NoError(t, err)
+
+	codes, err := util.GenerateRecoveryCodes(2)
+	require.NoError(t, err)
+
+	account.TotpSecret = secret
+	account.TotpEnabled = true
+	account.RecoveryCodes = []string{util.HashRecoveryCode(codes[0]), util.HashRecoveryCode(codes[1])}
+	return account, codes[0]
+}
+
+func currentTotpCode(t *testing.T, secret string) string {
+	code, err := util.TotpCode(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func TestVerifyLoginTotpAPI(t *testing.T) {
+	account, recoveryCode := randomTotpAccount(t)
+
+	testCases := []struct {
+		name          string
+		kind          string
+		code          func() string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			kind: token.MfaToken,
+			code: func() string { return currentTotpCode(t, account.TotpSecret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UseAccountTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UseAccountTotpStepParams) (db.Account, error) {
+						require.Equal(t, account.Username, arg.Username)
+						require.InDelta(t, time.Now().Unix()/30, arg.Step, 1)
+						return account, nil
+					})
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp responseLoginAccount
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.AccessToken)
+				require.True(t, rsp.User.TotpEnabled)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			kind: token.MfaToken,
+			code: func() string { return recoveryCode },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UseAccountRecoveryCode(gomock.Any(), gomock.Eq(db.UseAccountRecoveryCodeParams{
+						RecoveryCode: account.RecoveryCodes[0],
+						Username:     account.Username,
+					})).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			kind: token.MfaToken,
+			code: func() string { return "000000x" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UseAccountRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ReplayedCode",
+			kind: token.MfaToken,
+			code: func() string { return currentTotpCode(t, account.TotpSecret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UseAccountTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					UseAccountRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RecoveryCodeAlreadyUsed",
+			kind: token.MfaToken,
+			code: func() string { return recoveryCode },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Time{}, nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UseAccountRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "AccessTokenRejected",
+			kind: token.AccessToken,
+			code: func() string { return currentTotpCode(t, account.TotpSecret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "LockedOut",
+			kind: token.MfaToken,
+			code: func() string { return currentTotpCode(t, account.TotpSecret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(time.Now().Add(time.Minute), nil)
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			mfaToken, _, err := server.tokenMaker.CreateMfaToken(account.Username, time.Minute)
+			if tc.kind == token.AccessToken {
+				mfaToken, _, err = server.tokenMaker.CreateToken(account.Username, 0, "", time.Minute)
+			}
+			require.NoError(t, err)
+
+			data, err := json.Marshal(gin.H{
+				"mfa_token": mfaToken,
+				"code":      tc.code(),
+			})
+			require.NoError(t, err)
+
+			url := "/account/login/mfa"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestVerifyLoginTotpSingleUse(t *testing.T) {
+	account, _ := randomTotpAccount(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetLoginLockedUntil(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(time.Time{}, nil)
+	store.EXPECT().
+		GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+		Times(1).
+		Return(account, nil)
+	store.EXPECT().
+		UseAccountTotpStep(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(account, nil)
+	store.EXPECT().
+		DeleteLoginFailure(gomock.Any(), gomock.Any()).
+		Times(1)
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1)
+
+	server := newTestServer(t, store)
+	mfaToken, _, err := server.tokenMaker.CreateMfaToken(account.Username, time.Minute)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(gin.H{
+		"mfa_token": mfaToken,
+		"code":      currentTotpCode(t, account.TotpSecret),
+	})
+	require.NoError(t, err)
+
+	for _, expected := range []int{http.StatusOK, http.StatusUnauthorized} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "/account/login/mfa", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		server.route.ServeHTTP(recorder, request)
+		require.Equal(t, expected, recorder.Code)
+	}
+}
+
+func TestEnrollTotpAPI(t *testing.T) {
+	account, _ := randomAccount(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountTotp(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateAccountTotpParams) (db.Account, error) {
+						require.Equal(t, account.Username, arg.Username)
+						require.NotEmpty(t, arg.TotpSecret)
+						require.False(t, arg.TotpEnabled)
+						return account, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp enrollTotpResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.Secret)
+				require.Contains(t, rsp.OtpauthUri, rsp.Secret)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStubs: func(store *mockdb.MockStore) {
+				enabled := account
+				enabled.TotpEnabled = true
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					UpdateAccountTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/account/mfa/enroll"
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestConfirmTotpAPI(t *testing.T) {
+	account, _ := randomTotpAccount(t)
+	account.TotpEnabled = false
+	account.RecoveryCodes = []string{}
+
+	testCases := []struct {
+		name          string
+		code          func() string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			code: func() string { return currentTotpCode(t, account.TotpSecret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountTotp(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateAccountTotpParams) (db.Account, error) {
+						require.Equal(t, account.TotpSecret, arg.TotpSecret)
+						require.True(t, arg.TotpEnabled)
+						require.Len(t, arg.RecoveryCodes, recoveryCodesCount)
+						return account, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp confirmTotpResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp.RecoveryCodes, recoveryCodesCount)
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: func() string { return "abcdef" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NotEnrolled",
+			code: func() string { return currentTotpCode(t, account.TotpSecret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				notEnrolled := account
+				notEnrolled.TotpSecret = ""
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(notEnrolled, nil)
+				store.EXPECT().
+					UpdateAccountTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": tc.code()})
+			require.NoError(t, err)
+
+			url := "/account/mfa/confirm"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDisableTotpAPI(t *testing.T) {
+	account, _ := randomTotpAccount(t)
+
+	testCases := []struct {
+		name          string
+		code          func() string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			code: func() string { return currentTotpCode(t, account.TotpSecret) },
+			buildStubs: func(store *mockdb.MockStore) {
+				disabled := account
+				disabled.TotpSecret = ""
+				disabled.TotpEnabled = false
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UseAccountTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UpdateAccountTotp(gomock.Any(), gomock.Eq(db.UpdateAccountTotpParams{
+						Username:      account.Username,
+						TotpSecret:    "",
+						TotpEnabled:   false,
+						RecoveryCodes: []string{},
+					})).
+					Times(1).
+					Return(disabled, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: func() string { return "abcdef" },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					UseAccountRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpdateAccountTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": tc.code()})
+			require.NoError(t, err)
+
+			url := "/account/mfa/disable"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account.Username, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,23 +1,30 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/policy"
 	"github.com/gunhachi/poke-blackmarket/token"
+	"github.com/gunhachi/poke-blackmarket/util"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
+	authorizationTypeApiKey = "apikey"
 	authorizationPayloadKey = "authorization_payload"
 )
 
 // AuthMiddleware creates a gin middleware for authorization
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+func authMiddleware(tokenMaker token.Maker, revocations token.RevocationStore, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 
@@ -34,17 +41,45 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
+		var payload *token.Payload
+		var err error
+
 		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != authorizationTypeBearer {
+		switch authorizationType {
+		case authorizationTypeBearer:
+			payload, err = tokenMaker.VerifyToken(fields[1])
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			if payload.Kind == token.MfaToken {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errMfaPending))
+				return
+			}
+			if payload.Kind != token.AccessToken {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errNotAccessToken))
+				return
+			}
+		case authorizationTypeApiKey:
+			var status int
+			payload, status, err = verifyApiKey(ctx, store, fields[1])
+			if err != nil {
+				ctx.AbortWithStatusJSON(status, errorResponse(err))
+				return
+			}
+		default:
 			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
 
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		revoked, err := revocations.IsRevoked(ctx, payload.ID, payload.Username, payload.IssuedAt)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if revoked {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrRevokedToken))
 			return
 		}
 
@@ -52,3 +87,80 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// verifyApiKey checks the api key and builds the payload of its user and role,
+// the key creation time is used as issued time so revoking the account also rejects its keys
+func verifyApiKey(ctx *gin.Context, store db.Store, key string) (*token.Payload, int, error) {
+	prefix, err := util.ApiKeyPrefix(key)
+	if err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+
+	apiKey, err := store.GetApiKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, http.StatusUnauthorized, util.ErrInvalidApiKey
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	err = util.CheckApiKey(key, apiKey.HashedKey)
+	if err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+
+	if apiKey.RevokedAt.Valid {
+		return nil, http.StatusUnauthorized, errApiKeyRevoked
+	}
+
+	if apiKey.ExpiresAt.Valid && time.Now().After(apiKey.ExpiresAt.Time) {
+		return nil, http.StatusUnauthorized, errApiKeyExpired
+	}
+
+	err = store.UpdateApiKeyLastUsed(ctx, apiKey.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	payload := &token.Payload{
+		ID:        uuid.NewSHA1(uuid.NameSpaceOID, []byte(apiKey.Prefix)),
+		Username:  apiKey.Username,
+		UserID:    apiKey.UserID,
+		Role:      apiKey.Role,
+		Kind:      apiKeyKind,
+		IssuedAt:  apiKey.CreatedAt,
+		ExpiredAt: apiKey.ExpiresAt.Time,
+	}
+
+	return payload, http.StatusOK, nil
+}
+
+// sessionOnlyMiddleware creates a gin middleware rejecting api keys on the routes that mint or manage credentials,
+// so a leaked key cannot outlive its own expiry or revocation
+func sessionOnlyMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if payload.Kind == apiKeyKind {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errApiKeyNotAccepted))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// policyMiddleware creates a gin middleware that only let the roles permitted by the policy call the route
+func policyMiddleware(enforcer *policy.Policy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if !enforcer.Allowed(payload.Role, ctx.Request.Method, ctx.FullPath()) {
+			err := fmt.Errorf("role %q is not permitted to %s %s", payload.Role, ctx.Request.Method, ctx.FullPath())
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -8,7 +11,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/policy"
 	"github.com/gunhachi/poke-blackmarket/token"
+	"github.com/gunhachi/poke-blackmarket/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -20,7 +28,20 @@ func addAuthorization(
 	username string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(username, duration)
+	addUserAuthorization(t, request, tokenMaker, authorizationType, username, 0, "", duration)
+}
+
+func addUserAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	username string,
+	userID int64,
+	role string,
+	duration time.Duration,
+) {
+	token, payload, err := tokenMaker.CreateToken(username, userID, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -43,6 +64,28 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "MfaPendingToken_return_unauth",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				mfaToken, _, err := tokenMaker.CreateMfaToken("user", time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, mfaToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RefreshToken_return_unauth",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateRefreshToken("user", 0, "", time.Hour)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "NoAuthorization_return_unauth",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -88,7 +131,7 @@ func TestAuthMiddleware(t *testing.T) {
 			authPath := "/auth"
 			server.route.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.revocations, server.store),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -104,3 +147,379 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewareRevocation(t *testing.T) {
+	testCases := []struct {
+		name          string
+		revoke        func(t *testing.T, revocations token.RevocationStore, payload *token.Payload)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NotRevoked",
+			revoke: func(t *testing.T, revocations token.RevocationStore, payload *token.Payload) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RevokedToken",
+			revoke: func(t *testing.T, revocations token.RevocationStore, payload *token.Payload) {
+				err := revocations.RevokeToken(context.Background(), payload.ID, payload.Username, payload.ExpiredAt)
+				require.NoError(t, err)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevokedAccount",
+			revoke: func(t *testing.T, revocations token.RevocationStore, payload *token.Payload) {
+				err := revocations.RevokeAccount(context.Background(), payload.Username, time.Now())
+				require.NoError(t, err)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			authPath := "/auth"
+			server.route.GET(
+				authPath,
+				authMiddleware(server.tokenMaker, server.revocations, server.store),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			accessToken, payload, err := server.tokenMaker.CreateToken("user", 0, "", time.Minute)
+			require.NoError(t, err)
+			tc.revoke(t, server.revocations, payload)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPolicyMiddleware(t *testing.T) {
+	enforcer, err := policy.New(policy.Config{
+		Roles: map[string][]string{
+			policy.AnyRole: {"GET /policy/any"},
+			"LEAD":         {"GET /policy/:id"},
+		},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		path          string
+		role          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "PermittedRole",
+			path: "/policy/1",
+			role: "LEAD",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OtherRole",
+			path: "/policy/1",
+			role: "GRUNT",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NoUserSelected",
+			path: "/policy/1",
+			role: "",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AnyRole",
+			path: "/policy/any",
+			role: "",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			group := server.route.Group("/policy").Use(
+				authMiddleware(server.tokenMaker, server.revocations, server.store),
+				policyMiddleware(enforcer),
+			)
+			handler := func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			}
+			group.GET("/any", handler)
+			group.GET("/:id", handler)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", 1, tc.role, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthMiddlewareApiKey(t *testing.T) {
+	user := db.User{ID: util.RandomInt(1, 200), UserName: util.RandomUser(), UserRole: "GRUNT"}
+
+	testCases := []struct {
+		name          string
+		setupKey      func(t *testing.T) (db.ApiKey, string)
+		buildStubs    func(store *mockdb.MockStore, apiKey db.ApiKey)
+		revoke        func(t *testing.T, revocations token.RevocationStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupKey: func(t *testing.T) (db.ApiKey, string) {
+				return mockRandomApiKey(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.ApiKey) {
+				store.EXPECT().
+					GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					UpdateApiKeyLastUsed(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var payload token.Payload
+				err := json.Unmarshal(recorder.Body.Bytes(), &payload)
+				require.NoError(t, err)
+				require.Equal(t, user.UserName, payload.Username)
+				require.Equal(t, user.ID, payload.UserID)
+				require.Equal(t, user.UserRole, payload.Role)
+				require.Equal(t, apiKeyKind, payload.Kind)
+			},
+		},
+		{
+			name: "WrongSecret",
+			setupKey: func(t *testing.T) (db.ApiKey, string) {
+				apiKey, _ := mockRandomApiKey(t, user)
+				return apiKey, fmt.Sprintf("pbm_%s_%s", apiKey.Prefix, util.RandomString(64))
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.ApiKey) {
+				store.EXPECT().
+					GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					UpdateApiKeyLastUsed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownKey",
+			setupKey: func(t *testing.T) (db.ApiKey, string) {
+				return mockRandomApiKey(t, user)
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.ApiKey) {
+				store.EXPECT().
+					GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidFormat",
+			setupKey: func(t *testing.T) (db.ApiKey, string) {
+				return db.ApiKey{}, util.RandomString(32)
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.ApiKey) {
+				store.EXPECT().
+					GetApiKeyByPrefix(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevokedKey",
+			setupKey: func(t *testing.T) (db.ApiKey, string) {
+				apiKey, key := mockRandomApiKey(t, user)
+				apiKey.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				return apiKey, key
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.ApiKey) {
+				store.EXPECT().
+					GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					UpdateApiKeyLastUsed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredKey",
+			setupKey: func(t *testing.T) (db.ApiKey, string) {
+				apiKey, key := mockRandomApiKey(t, user)
+				apiKey.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+				return apiKey, key
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.ApiKey) {
+				store.EXPECT().
+					GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					UpdateApiKeyLastUsed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevokedAccount",
+			setupKey: func(t *testing.T) (db.ApiKey, string) {
+				apiKey, key := mockRandomApiKey(t, user)
+				apiKey.CreatedAt = time.Now().Add(-time.Hour)
+				return apiKey, key
+			},
+			buildStubs: func(store *mockdb.MockStore, apiKey db.ApiKey) {
+				store.EXPECT().
+					GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+					Times(1).
+					Return(apiKey, nil)
+				store.EXPECT().
+					UpdateApiKeyLastUsed(gomock.Any(), gomock.Eq(apiKey.ID)).
+					Times(1).
+					Return(nil)
+			},
+			revoke: func(t *testing.T, revocations token.RevocationStore) {
+				err := revocations.RevokeAccount(context.Background(), user.UserName, time.Now())
+				require.NoError(t, err)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiKey, key := tc.setupKey(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, apiKey)
+
+			server := newTestServer(t, store)
+			if tc.revoke != nil {
+				tc.revoke(t, server.revocations)
+			}
+
+			authPath := "/auth"
+			server.route.GET(
+				authPath,
+				authMiddleware(server.tokenMaker, server.revocations, server.store),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, ctx.MustGet(authorizationPayloadKey))
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("ApiKey %s", key))
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSessionOnlyRoutesApiKey(t *testing.T) {
+	user := db.User{ID: util.RandomInt(1, 200), UserName: util.RandomUser(), UserRole: "GRUNT"}
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPut, "/account/password"},
+		{http.MethodPost, "/account/user/select"},
+		{http.MethodPost, "/account/mfa/enroll"},
+		{http.MethodPost, "/account/mfa/confirm"},
+		{http.MethodPost, "/account/mfa/disable"},
+		{http.MethodPost, "/account/api-keys"},
+		{http.MethodGet, "/account/api-keys"},
+		{http.MethodDelete, "/account/api-keys/1"},
+	}
+
+	for i := range routes {
+		route := routes[i]
+
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			apiKey, key := mockRandomApiKey(t, user)
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+				Times(1).
+				Return(apiKey, nil)
+			store.EXPECT().
+				UpdateApiKeyLastUsed(gomock.Any(), gomock.Eq(apiKey.ID)).
+				Times(1).
+				Return(nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(route.method, route.path, nil)
+			require.NoError(t, err)
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeApiKey, key))
+
+			server.route.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+		})
+	}
+}
@@ -3,19 +3,21 @@ package api
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
 	"github.com/gunhachi/poke-blackmarket/token"
 )
 
-// createOrderRequest represent request payload for creating order
+// createOrderRequest represent request payload for creating order, the promotion code is optional
 type createOrderRequest struct {
-	UserID    int64 `json:"user_id" binding:"required"`
-	ProductID int64 `json:"product_id" binding:"required"`
-	Quantity  int32 `json:"quantity" binding:"required"`
+	UserID        int64  `json:"user_id"`
+	ProductID     int64  `json:"product_id" binding:"required"`
+	Quantity      int32  `json:"quantity" binding:"required,min=1"`
+	PromotionCode string `json:"promotion_code" binding:"omitempty,alphanum,max=32"`
 }
 
 // createOrder handler for creating order data and put the transaction into database layer
@@ -26,26 +28,28 @@ func (server *Server) createOrder(ctx *gin.Context) {
 		return
 	}
 
-	user, valid := server.validUser(ctx, req.UserID, "GRUNT")
+	authPayload, valid := authorizedUser(ctx, req.UserID)
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if user.UserName != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
-	}
-
 	arg := db.OrderTxParams{
-		UserID:    req.UserID,
-		ProductID: req.ProductID,
-		Quantity:  req.Quantity,
+		UserID:        authPayload.UserID,
+		ProductID:     req.ProductID,
+		Quantity:      req.Quantity,
+		PromotionCode: strings.ToUpper(req.PromotionCode),
 	}
 
 	order, err := server.store.OrderTx(ctx, arg)
 	if err != nil {
+		if status := promotionErrorStatus(err); status != 0 {
+			ctx.JSON(status, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrInsufficientStock) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -59,12 +63,13 @@ type getOrderRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-// getOrderUserIDReq represent id of user data for binding parameter to getOrder handler
+// getOrderUserIDReq represent id of user data for binding parameter to getOrder handler, the user selected on the token is used when empty
 type getOrderUserIDReq struct {
-	UserID int64 `json:"user_id" binding:"required"`
+	UserID int64 `json:"user_id"`
 }
 
-// getOrder handler of get order data based on given order id and responding user id
+// getOrder handler of get order data based on given order id and responding user id,
+// an order of another user is not found unless the session acts as a LEAD or ADMIN user
 func (server *Server) getOrder(ctx *gin.Context) {
 	var req getOrderRequest
 	var orderID getOrderUserIDReq
@@ -73,24 +78,60 @@ func (server *Server) getOrder(ctx *gin.Context) {
 		return
 	}
 
-	if err := ctx.ShouldBindJSON(&orderID); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&orderID); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
 	}
 
-	user, valid := server.validUser(ctx, orderID.UserID, "GRUNT")
+	authPayload, valid := authorizedUser(ctx, orderID.UserID)
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if user.UserName != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	order, err := server.store.GetPokemonOrderData(ctx, req.ID)
+	if err == nil && !orderVisible(authPayload, order) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+
+}
+
+// listOrderItems handler to list the lines of an order, an order of another user is not found as on getOrder
+func (server *Server) listOrderItems(ctx *gin.Context) {
+	var req getOrderRequest
+	var orderID getOrderUserIDReq
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&orderID); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	authPayload, valid := authorizedUser(ctx, orderID.UserID)
+	if !valid {
 		return
 	}
 
 	order, err := server.store.GetPokemonOrderData(ctx, req.ID)
+	if err == nil && !orderVisible(authPayload, order) {
+		err = sql.ErrNoRows
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -100,89 +141,171 @@ func (server *Server) getOrder(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, order)
+	items, err := server.store.ListOrderItems(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 
+	ctx.JSON(http.StatusOK, items)
 }
 
-// listOrderRequest represent parameter to list the order data
+// listOrderRequest represent parameter to list the order data, every filter is optional
 type listOrderRequest struct {
-	UserID   int64 `form:"user_id"`
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	UserID      int64     `form:"user_id" binding:"min=0"`
+	ProductID   int64     `form:"product_id" binding:"min=0"`
+	Status      string    `form:"status" binding:"omitempty,oneof=pending paid fulfilled cancelled refunded"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	MinTotal    int64     `form:"min_total" binding:"min=0"`
+	MaxTotal    int64     `form:"max_total" binding:"min=0"`
+	SortBy      string    `form:"sort_by" binding:"omitempty,oneof=id created_at total_price quantity"`
+	SortOrder   string    `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+	pageRequest
 }
 
-// listOrder handler to list the order on database
-func (server *Server) listOrder(ctx *gin.Context) {
+// listOrderParams binds the filters of the query, the selected user is required but any user can be filtered on
+func listOrderParams(ctx *gin.Context) (db.ListPokemonOrderDataParams, pageRequest, bool) {
 	var req listOrderRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+		return db.ListPokemonOrderDataParams{}, req.pageRequest, false
 	}
 
-	user, valid := server.validUser(ctx, req.UserID, "LEAD")
+	_, valid := authorizedUser(ctx, 0)
 	if !valid {
-		return
+		return db.ListPokemonOrderDataParams{}, req.pageRequest, false
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if user.UserName != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+	arg := db.ListPokemonOrderDataParams{
+		UserID:      sql.NullInt64{Int64: req.UserID, Valid: req.UserID != 0},
+		ProductID:   sql.NullInt64{Int64: req.ProductID, Valid: req.ProductID != 0},
+		Status:      sql.NullString{String: req.Status, Valid: req.Status != ""},
+		CreatedFrom: sql.NullTime{Time: req.CreatedFrom, Valid: !req.CreatedFrom.IsZero()},
+		CreatedTo:   sql.NullTime{Time: req.CreatedTo, Valid: !req.CreatedTo.IsZero()},
+		MinTotal:    sql.NullInt64{Int64: req.MinTotal, Valid: req.MinTotal != 0},
+		MaxTotal:    sql.NullInt64{Int64: req.MaxTotal, Valid: req.MaxTotal != 0},
+		SortField:   req.SortBy,
+		SortDesc:    req.SortOrder == "desc",
 	}
+	arg.Limit, arg.Offset = req.query()
 
-	arg := db.ListPokemonOrderDataParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	cursor, valid := bindCursor(ctx, req.pageRequest, arg.SortField, arg.SortDesc)
+	if !valid {
+		return db.ListPokemonOrderDataParams{}, req.pageRequest, false
 	}
 
-	orders, err := server.store.ListPokemonOrderData(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+	if cursor.ID != 0 {
+		arg.AfterID = sql.NullInt64{Int64: cursor.ID, Valid: true}
+		switch arg.SortField {
+		case "created_at":
+			arg.AfterCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		case "total_price", "quantity":
+			arg.AfterValue = sql.NullInt64{Int64: cursor.Value, Valid: true}
+		}
 	}
 
-	ctx.JSON(http.StatusOK, orders)
+	return arg, req.pageRequest, true
+}
+
+// orderCursor makes the cursor of the last order of a page, holding the field the orders are sorted by
+func orderCursor(arg db.ListPokemonOrderDataParams, id int64, createdAt time.Time, totalPrice int64, quantity int32) pageCursor {
+	cursor := pageCursor{
+		ID:       id,
+		SortBy:   arg.SortField,
+		SortDesc: arg.SortDesc,
+	}
+
+	switch arg.SortField {
+	case "created_at":
+		cursor.CreatedAt = createdAt
+	case "total_price":
+		cursor.Value = totalPrice
+	case "quantity":
+		cursor.Value = int64(quantity)
+	}
 
+	return cursor
 }
 
 // listOrder handler to list the order on database
-func (server *Server) listOrderDetailed(ctx *gin.Context) {
-	var req listOrderRequest
-	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+func (server *Server) listOrder(ctx *gin.Context) {
+	arg, page, valid := listOrderParams(ctx)
+	if !valid {
 		return
 	}
 
-	user, valid := server.validUser(ctx, req.UserID, "LEAD")
-	if !valid {
+	orders, err := server.store.ListPokemonOrderData(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if user.UserName != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if page.offsetPaged() {
+		ctx.JSON(http.StatusOK, orders)
 		return
 	}
 
-	arg := db.ListOrderDetailedDataParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	resp := listResponse{Data: orders}
+	if page.hasNextPage(len(orders)) {
+		orders = orders[:page.pageLimit()]
+		resp.Data = orders
+		last := orders[len(orders)-1]
+		resp.NextCursor = orderCursor(arg, last.ID, last.CreatedAt, last.TotalPrice, last.Quantity).encode()
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+
+}
+
+// listOrder handler to list the order on database
+func (server *Server) listOrderDetailed(ctx *gin.Context) {
+	arg, page, valid := listOrderParams(ctx)
+	if !valid {
+		return
 	}
 
-	orders, err := server.store.ListOrderDetailedData(ctx, arg)
+	orders, err := server.store.ListOrderDetailedData(ctx, db.ListOrderDetailedDataParams(arg))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, orders)
+	if page.offsetPaged() {
+		ctx.JSON(http.StatusOK, orders)
+		return
+	}
+
+	resp := listResponse{Data: orders}
+	if page.hasNextPage(len(orders)) {
+		orders = orders[:page.pageLimit()]
+		resp.Data = orders
+		last := orders[len(orders)-1]
+		resp.NextCursor = orderCursor(arg, last.ID, last.CreatedAt, last.TotalPrice, last.Quantity).encode()
+	}
+
+	ctx.JSON(http.StatusOK, resp)
 
 }
 
-// cancelOrder handler to cancel pokemon order data based on id
+// cancelOrder handler to cancel a pending pokemon order data of the selected user based on id, its stock is returned
 func (server *Server) cancelOrder(ctx *gin.Context) {
+	server.updateOrderStatus(ctx, db.OrderStatusCancelled, true)
+}
+
+// payOrder handler to mark a pending order of the selected user as paid
+func (server *Server) payOrder(ctx *gin.Context) {
+	server.updateOrderStatus(ctx, db.OrderStatusPaid, true)
+}
+
+// fulfillOrder handler to mark a paid order as handed over to the buyer
+func (server *Server) fulfillOrder(ctx *gin.Context) {
+	server.updateOrderStatus(ctx, db.OrderStatusFulfilled, false)
+}
+
+// updateOrderStatus moves the order of the uri to the given status, illegal transitions answer 409.
+// An order of another user than the selected one is not found when ownOrder is set
+func (server *Server) updateOrderStatus(ctx *gin.Context, status string, ownOrder bool) {
 	var req getOrderRequest
 	var orderID getOrderUserIDReq
 	if err := ctx.ShouldBindUri(&req); err != nil {
@@ -190,51 +313,66 @@ func (server *Server) cancelOrder(ctx *gin.Context) {
 		return
 	}
 
-	if err := ctx.ShouldBindJSON(&orderID); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&orderID); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
 	}
 
-	user, valid := server.validUser(ctx, orderID.UserID, "GRUNT")
+	authPayload, valid := authorizedUser(ctx, orderID.UserID)
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if user.UserName != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
+	arg := db.UpdateOrderStatusTxParams{
+		ID:     req.ID,
+		Status: status,
+	}
+	if ownOrder {
+		arg.UserID = authPayload.UserID
 	}
 
-	_, err := server.store.CancelOrderTx(ctx, db.CancelOrderParam{ID: req.ID})
+	result, err := server.store.UpdateOrderStatusTx(ctx, arg)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrInvalidOrderTransition) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"deleted": req.ID})
-
+	ctx.JSON(http.StatusOK, result.Order)
 }
 
-// validUser check whether user data valid based on param of id,user_name, and role
-func (server *Server) validUser(ctx *gin.Context, userID int64, role string) (db.User, bool) {
-	user, err := server.store.GetUserAccount(ctx, userID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return user, false
-		}
+// orderVisible reports whether the order belongs to the selected user, LEAD and ADMIN users see every order
+func orderVisible(authPayload *token.Payload, order db.PokeOrder) bool {
+	if authPayload.Role == roleLead || authPayload.Role == roleAdmin {
+		return true
+	}
+	return order.UserID == authPayload.UserID
+}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return user, false
+// authorizedUser check the user selected on the token match the given user id, the role is checked by the policy,
+// the selected user is used when no user id is given
+func authorizedUser(ctx *gin.Context, userID int64) (*token.Payload, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.UserID == 0 {
+		err := errors.New("no user selected for the session")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return authPayload, false
 	}
 
-	if user.UserRole != role {
-		err := fmt.Errorf("user [%d] role mismatch: %s vs %s", user.ID, user.UserRole, role)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return user, false
+	if userID != 0 && userID != authPayload.UserID {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return authPayload, false
 	}
 
-	return user, true
+	return authPayload, true
 }
@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
 	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
@@ -23,12 +25,16 @@ func TestGetOrderAPI(t *testing.T) {
 	testCases := []struct {
 		name          string
 		ID            int64
+		userID        int64
+		role          string
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
 	}{
 		{
-			name: "Succes_GetOrder_API_nil_error",
-			ID:   order.ID,
+			name:   "Succes_GetOrder_API_nil_error",
+			ID:     order.ID,
+			userID: order.UserID,
+			role:   roleGrunt,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetPokemonOrderData(gomock.Any(), gomock.Eq(order.ID)).
@@ -41,8 +47,10 @@ func TestGetOrderAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "NotFound_GetOrder_API_with_error",
-			ID:   order.ID,
+			name:   "NotFound_GetOrder_API_with_error",
+			ID:     order.ID,
+			userID: order.UserID,
+			role:   roleGrunt,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetPokemonOrderData(gomock.Any(), gomock.Eq(order.ID)).
@@ -54,8 +62,41 @@ func TestGetOrderAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "InternalError_GetOrder_API_with_error",
-			ID:   order.ID,
+			name:   "OtherUser_GetOrder_API_not_found",
+			ID:     order.ID,
+			userID: order.UserID + 1,
+			role:   roleGrunt,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPokemonOrderData(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(order, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "LeadUser_GetOrder_API_nil_error",
+			ID:     order.ID,
+			userID: order.UserID + 1,
+			role:   roleLead,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPokemonOrderData(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(order, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				reqBodyOrder(t, recorder.Body, order)
+			},
+		},
+		{
+			name:   "InternalError_GetOrder_API_with_error",
+			ID:     order.ID,
+			userID: order.UserID,
+			role:   roleGrunt,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetPokemonOrderData(gomock.Any(), gomock.Eq(order.ID)).
@@ -67,8 +108,10 @@ func TestGetOrderAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "InvalidID_GetOrder_API_with_error",
-			ID:   0,
+			name:   "InvalidID_GetOrder_API_with_error",
+			ID:     0,
+			userID: order.UserID,
+			role:   roleGrunt,
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetPokemonOrderData(gomock.Any(), gomock.Any()).
@@ -97,6 +140,7 @@ func TestGetOrderAPI(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), tc.userID, tc.role, time.Minute)
 			server.route.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -214,6 +258,102 @@ func TestGetOrderAPI(t *testing.T) {
 // 	}
 // }
 
+func TestCreateOrderStockAPI(t *testing.T) {
+	order := mockRandomOrder()
+
+	testCases := []struct {
+		name          string
+		quantity      int32
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "InStock",
+			quantity: order.Quantity,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					OrderTx(gomock.Any(), gomock.Eq(db.OrderTxParams{
+						UserID:    order.UserID,
+						ProductID: order.ProductID.Int64,
+						Quantity:  order.Quantity,
+					})).
+					Times(1).
+					Return(db.OrderTxResult{Order: order}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InsufficientStock",
+			quantity: order.Quantity,
+			buildStubs: func(store *mockdb.MockStore) {
+				err := fmt.Errorf("%w: pikachu has 0 left, 1 requested", db.ErrInsufficientStock)
+				store.EXPECT().
+					OrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OrderTxResult{}, err)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "NegativeQuantity",
+			quantity: -1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					OrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			quantity: order.Quantity,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					OrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OrderTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"product_id": order.ProductID.Int64,
+				"quantity":   tc.quantity,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/order", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), order.UserID, "GRUNT", time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestListOrderAPI(t *testing.T) {
 
 	n := 5
@@ -325,21 +465,179 @@ func TestListOrderAPI(t *testing.T) {
 			q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
 			request.URL.RawQuery = q.Encode()
 
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), util.RandomInt(1, 300), "LEAD", time.Minute)
 			server.route.ServeHTTP(recorder, request)
 			tc.checkResponse(recorder)
 		})
 	}
 }
 
+func TestUpdateOrderStatusAPI(t *testing.T) {
+	order := mockRandomOrder()
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "PayOrder",
+			method: http.MethodPost,
+			path:   fmt.Sprintf("/order/%d/pay", order.ID),
+			role:   "GRUNT",
+			buildStubs: func(store *mockdb.MockStore) {
+				paid := order
+				paid.Status = db.OrderStatusPaid
+				store.EXPECT().
+					UpdateOrderStatusTx(gomock.Any(), gomock.Eq(db.UpdateOrderStatusTxParams{
+						ID:     order.ID,
+						UserID: order.UserID,
+						Status: db.OrderStatusPaid,
+					})).
+					Times(1).
+					Return(db.OrderTxResult{Order: paid}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				paid := order
+				paid.Status = db.OrderStatusPaid
+				reqBodyOrder(t, recorder.Body, paid)
+			},
+		},
+		{
+			name:   "FulfillOrder",
+			method: http.MethodPost,
+			path:   fmt.Sprintf("/order/%d/fulfill", order.ID),
+			role:   "LEAD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateOrderStatusTx(gomock.Any(), gomock.Eq(db.UpdateOrderStatusTxParams{
+						ID:     order.ID,
+						Status: db.OrderStatusFulfilled,
+					})).
+					Times(1).
+					Return(db.OrderTxResult{Order: order}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "FulfillOrderNotLead",
+			method: http.MethodPost,
+			path:   fmt.Sprintf("/order/%d/fulfill", order.ID),
+			role:   "GRUNT",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateOrderStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "CancelOrderTwice",
+			method: http.MethodDelete,
+			path:   fmt.Sprintf("/order/%d", order.ID),
+			role:   "GRUNT",
+			buildStubs: func(store *mockdb.MockStore) {
+				err := fmt.Errorf("%w: cancelled to cancelled", db.ErrInvalidOrderTransition)
+				store.EXPECT().
+					UpdateOrderStatusTx(gomock.Any(), gomock.Eq(db.UpdateOrderStatusTxParams{
+						ID:     order.ID,
+						UserID: order.UserID,
+						Status: db.OrderStatusCancelled,
+					})).
+					Times(1).
+					Return(db.OrderTxResult{}, err)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			method: http.MethodPost,
+			path:   fmt.Sprintf("/order/%d/pay", order.ID),
+			role:   "GRUNT",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateOrderStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OrderTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			method: http.MethodPost,
+			path:   fmt.Sprintf("/order/%d/pay", order.ID),
+			role:   "GRUNT",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateOrderStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OrderTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidID",
+			method: http.MethodPost,
+			path:   "/order/0/pay",
+			role:   "GRUNT",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateOrderStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.path, nil)
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), order.UserID, tc.role, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 // mockRandomOrder create random user data
 func mockRandomOrder() db.PokeOrder {
 	return db.PokeOrder{
-		ID:          util.RandomInt(1, 200),
-		UserID:      util.RandomInt(1, 300),
-		ProductID:   util.RandomInt(1, 300),
-		Quantity:    int32(util.RandomInt(1, 10)),
-		TotalPrice:  util.RandomAmount(),
-		OrderDetail: "selling",
+		ID:         util.RandomInt(1, 200),
+		UserID:     util.RandomInt(1, 300),
+		ProductID:  sql.NullInt64{Int64: util.RandomInt(1, 300), Valid: true},
+		Quantity:   int32(util.RandomInt(1, 10)),
+		TotalPrice: util.RandomAmount(),
+		Status:     db.OrderStatusPending,
 	}
 }
 
@@ -364,3 +662,160 @@ func reqBodyOrders(t *testing.T, body *bytes.Buffer, params []db.PokeOrder) {
 	require.NoError(t, err)
 	require.Equal(t, params, gotData)
 }
+
+func TestListOrderFilterAPI(t *testing.T) {
+	createdFrom := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	createdTo := createdFrom.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name          string
+		path          string
+		query         map[string]string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AllFilters",
+			path: "/order",
+			query: map[string]string{
+				"user_id":      "7",
+				"product_id":   "3",
+				"status":       db.OrderStatusPaid,
+				"created_from": createdFrom.Format(time.RFC3339),
+				"created_to":   createdTo.Format(time.RFC3339),
+				"min_total":    "100",
+				"max_total":    "900",
+				"sort_by":      "total_price",
+				"sort_order":   "desc",
+				"page_id":      "2",
+				"page_size":    "5",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonOrderData(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ListPokemonOrderDataParams) ([]db.PokeOrder, error) {
+						require.Equal(t, sql.NullInt64{Int64: 7, Valid: true}, arg.UserID)
+						require.Equal(t, sql.NullInt64{Int64: 3, Valid: true}, arg.ProductID)
+						require.Equal(t, sql.NullString{String: db.OrderStatusPaid, Valid: true}, arg.Status)
+						require.True(t, arg.CreatedFrom.Valid)
+						require.True(t, createdFrom.Equal(arg.CreatedFrom.Time))
+						require.True(t, arg.CreatedTo.Valid)
+						require.True(t, createdTo.Equal(arg.CreatedTo.Time))
+						require.Equal(t, sql.NullInt64{Int64: 100, Valid: true}, arg.MinTotal)
+						require.Equal(t, sql.NullInt64{Int64: 900, Valid: true}, arg.MaxTotal)
+						require.Equal(t, "total_price", arg.SortField)
+						require.True(t, arg.SortDesc)
+						require.Equal(t, int32(5), arg.Limit)
+						require.Equal(t, int32(5), arg.Offset)
+						return []db.PokeOrder{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DetailedFilters",
+			path: "/order-detailed",
+			query: map[string]string{
+				"status":    db.OrderStatusPending,
+				"sort_by":   "created_at",
+				"page_id":   "1",
+				"page_size": "5",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOrderDetailedData(gomock.Any(), gomock.Eq(db.ListOrderDetailedDataParams{
+						Status:    sql.NullString{String: db.OrderStatusPending, Valid: true},
+						SortField: "created_at",
+						Limit:     5,
+					})).
+					Times(1).
+					Return([]db.ListOrderDetailedDataRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidStatus",
+			path: "/order",
+			query: map[string]string{
+				"status":    "lost",
+				"page_id":   "1",
+				"page_size": "5",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonOrderData(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidSortField",
+			path: "/order",
+			query: map[string]string{
+				"sort_by":   "user_id; DROP TABLE poke_orders",
+				"page_id":   "1",
+				"page_size": "5",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonOrderData(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCreatedFrom",
+			path: "/order",
+			query: map[string]string{
+				"created_from": "yesterday",
+				"page_id":      "1",
+				"page_size":    "5",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonOrderData(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			for key, value := range tc.query {
+				q.Add(key, value)
+			}
+			request.URL.RawQuery = q.Encode()
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), util.RandomInt(1, 300), "LEAD", time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+var (
+	errInvalidCursor   = errors.New("invalid cursor")
+	errCursorSortMoved = errors.New("cursor was made for another sort order")
+)
+
+// pageRequest represent the pagination query shared by the list endpoints,
+// a page_id answers the offset page as before, otherwise the rows after the cursor are listed
+type pageRequest struct {
+	PageID   int32  `form:"page_id" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"required_with=PageID,omitempty,min=5,max=10"`
+	After    string `form:"after"`
+	Limit    int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// offsetPaged tells whether the request asked for an offset page by page_id
+func (req pageRequest) offsetPaged() bool {
+	return req.PageID > 0
+}
+
+// pageLimit is the number of rows of a cursor page
+func (req pageRequest) pageLimit() int32 {
+	if req.Limit == 0 {
+		return defaultPageLimit
+	}
+	return req.Limit
+}
+
+// query returns the limit and offset of the list query,
+// a cursor page fetches one more row to know whether another page follows
+func (req pageRequest) query() (int32, int32) {
+	if req.offsetPaged() {
+		return req.PageSize, (req.PageID - 1) * req.PageSize
+	}
+	return req.pageLimit() + 1, 0
+}
+
+// hasNextPage tells whether the query fetched a row past the page,
+// the caller trims it off and makes the next cursor from the last row left
+func (req pageRequest) hasNextPage(count int) bool {
+	return !req.offsetPaged() && count > int(req.pageLimit())
+}
+
+// pageCursor is the position of the last row of a page, handed to the client as an opaque string.
+// The sort of the listing is kept so the cursor is not used to walk another order
+type pageCursor struct {
+	ID        int64     `json:"id"`
+	SortBy    string    `json:"sort_by,omitempty"`
+	SortDesc  bool      `json:"sort_desc,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Value     int64     `json:"value,omitempty"`
+}
+
+// encode turns the cursor into the opaque next_cursor value
+func (cursor pageCursor) encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// bindCursor decodes the after cursor of the request, the zero cursor starts from the first row.
+// A malformed cursor or one made for another sort answers 400
+func bindCursor(ctx *gin.Context, req pageRequest, sortBy string, sortDesc bool) (pageCursor, bool) {
+	var cursor pageCursor
+	if req.offsetPaged() || req.After == "" {
+		return cursor, true
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(req.After)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.ID < 1 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidCursor))
+		return cursor, false
+	}
+
+	if cursor.SortBy != sortBy || cursor.SortDesc != sortDesc {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errCursorSortMoved))
+		return cursor, false
+	}
+
+	return cursor, true
+}
+
+// listResponse is the envelope of a cursor page, next_cursor is empty on the last page
+type listResponse struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor"`
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPokemonCursorAPI(t *testing.T) {
+	pokes := make([]db.PokeProduct, 3)
+	for i := range pokes {
+		pokes[i] = mockRandomPoke()
+	}
+
+	testCases := []struct {
+		name          string
+		query         map[string]string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			query: map[string]string{"limit": "2"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonData(gomock.Any(), gomock.Eq(db.ListPokemonDataParams{Limit: 3})).
+					Times(1).
+					Return(pokes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page struct {
+					Data       []db.PokeProduct `json:"data"`
+					NextCursor string           `json:"next_cursor"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.Equal(t, pokes[:2], page.Data)
+				require.Equal(t, pageCursor{ID: pokes[1].ID}, decodeTestCursor(t, page.NextCursor))
+			},
+		},
+		{
+			name: "LastPage",
+			query: map[string]string{
+				"after": pageCursor{ID: 9}.encode(),
+				"limit": "5",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListPokemonDataParams{
+					AfterID: sql.NullInt64{Int64: 9, Valid: true},
+					Limit:   6,
+				}
+				store.EXPECT().
+					ListPokemonData(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(pokes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.Len(t, page["data"], len(pokes))
+				require.Equal(t, "", page["next_cursor"])
+			},
+		},
+		{
+			name:  "DefaultLimit",
+			query: map[string]string{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonData(gomock.Any(), gomock.Eq(db.ListPokemonDataParams{Limit: defaultPageLimit + 1})).
+					Times(1).
+					Return([]db.PokeProduct{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"data":[],"next_cursor":""}`, recorder.Body.String())
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: map[string]string{"after": "not a cursor"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonData(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "CursorOfAnotherSort",
+			query: map[string]string{"after": pageCursor{ID: 9, SortBy: "total_price"}.encode()},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonData(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "LimitTooLarge",
+			query: map[string]string{"limit": "1000"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonData(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/pokemon", nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			for key, value := range tc.query {
+				q.Add(key, value)
+			}
+			request.URL.RawQuery = q.Encode()
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), util.RandomInt(1, 300), "GRUNT", time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListOrderCursorAPI(t *testing.T) {
+	orders := []db.PokeOrder{mockRandomOrder(), mockRandomOrder()}
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		path          string
+		query         map[string]string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "SortedByTotalPrice",
+			path: "/order",
+			query: map[string]string{
+				"sort_by":    "total_price",
+				"sort_order": "desc",
+				"after":      pageCursor{ID: 5, SortBy: "total_price", SortDesc: true, Value: 300}.encode(),
+				"limit":      "1",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonOrderData(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ListPokemonOrderDataParams) ([]db.PokeOrder, error) {
+						require.Equal(t, sql.NullInt64{Int64: 5, Valid: true}, arg.AfterID)
+						require.Equal(t, sql.NullInt64{Int64: 300, Valid: true}, arg.AfterValue)
+						require.False(t, arg.AfterCreatedAt.Valid)
+						require.Equal(t, int32(2), arg.Limit)
+						require.Equal(t, int32(0), arg.Offset)
+						return orders, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page struct {
+					Data       []db.PokeOrder `json:"data"`
+					NextCursor string         `json:"next_cursor"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.Len(t, page.Data, 1)
+				require.Equal(t, pageCursor{
+					ID:       orders[0].ID,
+					SortBy:   "total_price",
+					SortDesc: true,
+					Value:    orders[0].TotalPrice,
+				}, decodeTestCursor(t, page.NextCursor))
+			},
+		},
+		{
+			name: "DetailedSortedByCreatedAt",
+			path: "/order-detailed",
+			query: map[string]string{
+				"sort_by": "created_at",
+				"after":   pageCursor{ID: 5, SortBy: "created_at", CreatedAt: createdAt}.encode(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOrderDetailedData(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ListOrderDetailedDataParams) ([]db.ListOrderDetailedDataRow, error) {
+						require.Equal(t, sql.NullInt64{Int64: 5, Valid: true}, arg.AfterID)
+						require.True(t, arg.AfterCreatedAt.Valid)
+						require.True(t, createdAt.Equal(arg.AfterCreatedAt.Time))
+						require.False(t, arg.AfterValue.Valid)
+						require.Equal(t, int32(defaultPageLimit+1), arg.Limit)
+						return []db.ListOrderDetailedDataRow{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"data":[],"next_cursor":""}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "CursorOfAnotherSort",
+			path: "/order",
+			query: map[string]string{
+				"sort_by": "created_at",
+				"after":   pageCursor{ID: 5, SortBy: "total_price", SortDesc: true, Value: 300}.encode(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPokemonOrderData(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			for key, value := range tc.query {
+				q.Add(key, value)
+			}
+			request.URL.RawQuery = q.Encode()
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), util.RandomInt(1, 300), "LEAD", time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// decodeTestCursor reads back the cursor handed out as next_cursor
+func decodeTestCursor(t *testing.T, value string) pageCursor {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	require.NoError(t, err)
+
+	var cursor pageCursor
+	require.NoError(t, json.Unmarshal(data, &cursor))
+	return cursor
+}
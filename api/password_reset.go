@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/notify"
+	"github.com/gunhachi/poke-blackmarket/util"
+)
+
+const passwordResetTokenBytes = 32
+
+var errInvalidResetToken = errors.New("invalid or expired reset token")
+
+// forgotPasswordRequest represent param to request a password reset
+type forgotPasswordRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+}
+
+// forgotPasswordResponse is the same whether the account exists or not
+type forgotPasswordResponse struct {
+	Message string `json:"message"`
+}
+
+// forgotPassword handler to send a single use reset token to the owner of the account
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rsp := forgotPasswordResponse{
+		Message: "if the account exists, a reset token has been sent to its owner",
+	}
+
+	account, err := server.store.GetAccountLog(ctx, req.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusAccepted, rsp)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if account.IsDisabled {
+		ctx.JSON(http.StatusAccepted, rsp)
+		return
+	}
+
+	resetToken, err := util.RandomSecret(passwordResetTokenBytes)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	reset, err := server.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		HashedToken: util.HashSecret(resetToken),
+		Username:    account.Username,
+		ExpiresAt:   time.Now().Add(server.config.PasswordResetDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.notifier.Notify(ctx, notify.Message{
+		To:      account.Username,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"use the reset token %s on /account/password/reset before %s",
+			resetToken,
+			reset.ExpiresAt.Format(time.RFC3339),
+		),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, rsp)
+}
+
+// resetPasswordRequest represent param to set a new password with a reset token
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// resetPassword handler to consume a reset token and change the password, every session and token
+// of the account issued before the change is rejected afterward, the sessions are blocked by ResetPasswordTx
+// and the tokens are revoked through the revocation store once it commits
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.passwordPolicy.Validate(req.NewPassword); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := server.hasher.Hash(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		HashedToken:    util.HashSecret(req.Token),
+		HashedPassword: hashedPassword,
+		ChangedAt:      time.Now(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidResetToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	account := result.Account
+	err = server.revocations.RevokeAccount(ctx, account.Username, account.PasswordChangetAt.Time)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.resetLoginFailures(ctx, account.Username) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildAccountResponse(account))
+}
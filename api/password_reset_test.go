@@ -0,0 +1,283 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/notify"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+// recordNotifier keeps the delivered messages in memory
+type recordNotifier struct {
+	messages []notify.Message
+}
+
+func (notifier *recordNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	notifier.messages = append(notifier.messages, msg)
+	return nil
+}
+
+func TestForgotPasswordAPI(t *testing.T) {
+	account, _ := randomAccount(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, notifier *recordNotifier)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"username": account.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+						require.Equal(t, account.Username, arg.Username)
+						require.Len(t, arg.HashedToken, 64)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+						return db.PasswordReset{
+							HashedToken: arg.HashedToken,
+							Username:    arg.Username,
+							ExpiresAt:   arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordNotifier) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Len(t, notifier.messages, 1)
+				require.Equal(t, account.Username, notifier.messages[0].To)
+				require.Regexp(t, regexp.MustCompile(`[0-9a-f]{64}`), notifier.messages[0].Body)
+			},
+		},
+		{
+			name: "UnknownAccount",
+			body: gin.H{"username": account.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordNotifier) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, notifier.messages)
+			},
+		},
+		{
+			name: "DisabledAccount",
+			body: gin.H{"username": account.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				disabled := account
+				disabled.IsDisabled = true
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Eq(account.Username)).
+					Times(1).
+					Return(disabled, nil)
+				store.EXPECT().
+					CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordNotifier) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Empty(t, notifier.messages)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"username": account.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordNotifier) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidUsername",
+			body: gin.H{"username": "invalid-user#1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, notifier *recordNotifier) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			notifier := &recordNotifier{}
+			server.notifier = notifier
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/account/password/forgot"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, notifier)
+		})
+	}
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	account, _ := randomAccount(t)
+	newPassword := util.RandomString(8)
+	resetToken, err := util.RandomSecret(passwordResetTokenBytes)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"token":        resetToken,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := account
+				updated.PasswordChangetAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+						require.Equal(t, util.HashSecret(resetToken), arg.HashedToken)
+						require.NoError(t, util.CheckPassword(newPassword, arg.HashedPassword))
+						return db.ResetPasswordTxResult{Account: updated}, nil
+					})
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Eq(db.DeleteLoginFailureParams{
+						Scope:   loginScopeUsername,
+						Subject: account.Username,
+					})).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				revoked, err := server.revocations.IsRevoked(context.Background(), uuid.New(), account.Username, time.Now().Add(-time.Minute))
+				require.NoError(t, err)
+				require.True(t, revoked)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: gin.H{
+				"token":        resetToken,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrNoRows)
+				store.EXPECT().
+					BlockAccountSessions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "WeakPassword",
+			body: gin.H{
+				"token":        resetToken,
+				"new_password": "123",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"token":        resetToken,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/account/password/reset"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server)
+		})
+	}
+}
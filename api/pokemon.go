@@ -14,10 +14,13 @@ type createPokemonRequest struct {
 	PokeName  string `json:"poke_name" binding:"required"`
 	Status    string `json:"status" binding:"required"`
 	PokePrice int64  `json:"poke_price" binding:"required"`
-	PokeStock int64  `json:"poke_stock" binding:"required"`
+	PokeStock int64  `json:"poke_stock" binding:"required,min=0"`
+	Currency  string `json:"currency" binding:"omitempty,len=3,alpha,uppercase"`
+	PokeType  string `json:"poke_type"`
 }
 
-// createPokemon handler to create pokemon data on given request
+// createPokemon handler to create pokemon data on given request,
+// the price is in minor units of the currency which defaults to USD
 func (server *Server) createPokemon(ctx *gin.Context) {
 	var req createPokemonRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -25,11 +28,17 @@ func (server *Server) createPokemon(ctx *gin.Context) {
 		return
 	}
 
+	if req.Currency == "" {
+		req.Currency = db.DefaultCurrency
+	}
+
 	arg := db.CreatePokemonDataParams{
 		PokeName:  req.PokeName,
 		Status:    req.Status,
 		PokePrice: req.PokePrice,
 		PokeStock: req.PokeStock,
+		Currency:  req.Currency,
+		PokeType:  req.PokeType,
 	}
 
 	poke, err := server.store.CreatePokemonData(ctx, arg)
@@ -103,8 +112,7 @@ func (server *Server) getDataPokemonApi(ctx *gin.Context) {
 
 // listPokeRequest represent listing parameter
 type listPokeRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	pageRequest
 }
 
 // listPokemon handler to execute get list of pokemon
@@ -115,10 +123,15 @@ func (server *Server) listPokemon(ctx *gin.Context) {
 		return
 	}
 
+	cursor, valid := bindCursor(ctx, req.pageRequest, "", false)
+	if !valid {
+		return
+	}
+
 	arg := db.ListPokemonDataParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		AfterID: sql.NullInt64{Int64: cursor.ID, Valid: cursor.ID != 0},
 	}
+	arg.Limit, arg.Offset = req.query()
 
 	pokes, err := server.store.ListPokemonData(ctx, arg)
 	if err != nil {
@@ -126,16 +139,29 @@ func (server *Server) listPokemon(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, pokes)
+	if req.offsetPaged() {
+		ctx.JSON(http.StatusOK, pokes)
+		return
+	}
+
+	resp := listResponse{Data: pokes}
+	if req.hasNextPage(len(pokes)) {
+		pokes = pokes[:req.pageLimit()]
+		resp.Data = pokes
+		resp.NextCursor = pageCursor{ID: pokes[len(pokes)-1].ID}.encode()
+	}
+
+	ctx.JSON(http.StatusOK, resp)
 
 }
 
-// updatePokemonData represent update pokemon data parameter
+// updatePokemonData represent update pokemon data parameter, the type is kept when empty
 type updatePokemonData struct {
 	// ID        int64  `json:"id" binding:"required,min=1"`
 	Status    string `json:"status"`
 	PokePrice int64  `json:"poke_price"`
-	PokeStock int64  `json:"poke_stock"`
+	PokeStock int64  `json:"poke_stock" binding:"min=0"`
+	PokeType  string `json:"poke_type"`
 }
 
 // updatePokemon handler to update data pokemon
@@ -157,6 +183,7 @@ func (server *Server) updatePokemon(ctx *gin.Context) {
 		Status:    dataReq.Status,
 		PokePrice: dataReq.PokePrice,
 		PokeStock: dataReq.PokeStock,
+		PokeType:  sql.NullString{String: dataReq.PokeType, Valid: dataReq.PokeType != ""},
 	}
 
 	poke, err := server.store.UpdatePokemonData(ctx, arg)
@@ -140,6 +140,7 @@ func TestCreatePokemonAPI(t *testing.T) {
 					Status:    "good",
 					PokePrice: 2000,
 					PokeStock: 2,
+					Currency:  db.DefaultCurrency,
 				}
 
 				store.EXPECT().
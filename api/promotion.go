@@ -0,0 +1,270 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/token"
+	"github.com/lib/pq"
+)
+
+// Different types of error returned by the promotion validation
+var (
+	errPromotionPercentage = errors.New("a percentage discount cannot exceed 100")
+	errPromotionCurrency   = errors.New("currency is only set on a fixed_amount discount")
+	errPromotionWindow     = errors.New("ends_at must be after starts_at")
+)
+
+// createPromotionRequest represent request payload for a promotion code, every scope and limit is optional.
+// A fixed amount is in minor units of its currency, USD by default
+type createPromotionRequest struct {
+	Code                  string     `json:"code" binding:"required,alphanum,max=32"`
+	Description           string     `json:"description" binding:"max=255"`
+	DiscountType          string     `json:"discount_type" binding:"required,oneof=percentage fixed_amount"`
+	DiscountValue         int64      `json:"discount_value" binding:"required,min=1"`
+	Currency              string     `json:"currency" binding:"omitempty,len=3,alpha,uppercase"`
+	ProductID             int64      `json:"product_id" binding:"min=0"`
+	PokeType              string     `json:"poke_type"`
+	UserRole              string     `json:"user_role" binding:"omitempty,oneof=GRUNT LEAD ADMIN"`
+	StartsAt              *time.Time `json:"starts_at"`
+	EndsAt                *time.Time `json:"ends_at"`
+	MaxRedemptions        int32      `json:"max_redemptions" binding:"min=0"`
+	MaxRedemptionsPerUser int32      `json:"max_redemptions_per_user" binding:"min=0"`
+}
+
+// promotionResponse represent a promotion, the scopes and limits left out are not applied
+type promotionResponse struct {
+	ID                    int64      `json:"id"`
+	Code                  string     `json:"code"`
+	Description           string     `json:"description"`
+	DiscountType          string     `json:"discount_type"`
+	DiscountValue         int64      `json:"discount_value"`
+	Currency              string     `json:"currency,omitempty"`
+	ProductID             int64      `json:"product_id,omitempty"`
+	PokeType              string     `json:"poke_type,omitempty"`
+	UserRole              string     `json:"user_role,omitempty"`
+	StartsAt              time.Time  `json:"starts_at"`
+	EndsAt                *time.Time `json:"ends_at"`
+	MaxRedemptions        int32      `json:"max_redemptions,omitempty"`
+	MaxRedemptionsPerUser int32      `json:"max_redemptions_per_user,omitempty"`
+	RedemptionCount       int32      `json:"redemption_count"`
+	CreatedBy             string     `json:"created_by"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+// buildPromotionResponse build expected response
+func buildPromotionResponse(promo db.Promotion) promotionResponse {
+	return promotionResponse{
+		ID:                    promo.ID,
+		Code:                  promo.Code,
+		Description:           promo.Description,
+		DiscountType:          promo.DiscountType,
+		DiscountValue:         promo.DiscountValue,
+		Currency:              promo.Currency.String,
+		ProductID:             promo.ProductID.Int64,
+		PokeType:              promo.PokeType.String,
+		UserRole:              promo.UserRole.String,
+		StartsAt:              promo.StartsAt,
+		EndsAt:                nullTime(promo.EndsAt),
+		MaxRedemptions:        promo.MaxRedemptions.Int32,
+		MaxRedemptionsPerUser: promo.MaxRedemptionsPerUser.Int32,
+		RedemptionCount:       promo.RedemptionCount,
+		CreatedBy:             promo.CreatedBy,
+		CreatedAt:             promo.CreatedAt,
+	}
+}
+
+// createPromotion handler to create a promotion code, the code is stored upper case
+func (server *Server) createPromotion(ctx *gin.Context) {
+	var req createPromotionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.DiscountType == db.PromotionPercentage && req.DiscountValue > 100 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errPromotionPercentage))
+		return
+	}
+
+	if req.DiscountType == db.PromotionPercentage && req.Currency != "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errPromotionCurrency))
+		return
+	}
+	if req.DiscountType == db.PromotionFixedAmount && req.Currency == "" {
+		req.Currency = db.DefaultCurrency
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(startsAt) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errPromotionWindow))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreatePromotionParams{
+		Code:                  strings.ToUpper(req.Code),
+		Description:           req.Description,
+		DiscountType:          req.DiscountType,
+		DiscountValue:         req.DiscountValue,
+		Currency:              sql.NullString{String: req.Currency, Valid: req.Currency != ""},
+		ProductID:             sql.NullInt64{Int64: req.ProductID, Valid: req.ProductID != 0},
+		PokeType:              sql.NullString{String: req.PokeType, Valid: req.PokeType != ""},
+		UserRole:              sql.NullString{String: req.UserRole, Valid: req.UserRole != ""},
+		StartsAt:              startsAt,
+		MaxRedemptions:        sql.NullInt32{Int32: req.MaxRedemptions, Valid: req.MaxRedemptions != 0},
+		MaxRedemptionsPerUser: sql.NullInt32{Int32: req.MaxRedemptionsPerUser, Valid: req.MaxRedemptionsPerUser != 0},
+		CreatedBy:             authPayload.Username,
+	}
+	if req.EndsAt != nil {
+		arg.EndsAt = sql.NullTime{Time: *req.EndsAt, Valid: true}
+	}
+
+	promo, err := server.store.CreatePromotion(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation", "foreign_key_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildPromotionResponse(promo))
+}
+
+// getPromotionRequest bind for id on promotion data
+type getPromotionRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getPromotion handler to get a promotion with its redemption count
+func (server *Server) getPromotion(ctx *gin.Context) {
+	var req getPromotionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	promo, err := server.store.GetPromotion(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildPromotionResponse(promo))
+}
+
+// listPromotionRequest represent listing parameter
+type listPromotionRequest struct {
+	pageRequest
+}
+
+// listPromotions handler to list the promotions
+func (server *Server) listPromotions(ctx *gin.Context) {
+	var req listPromotionRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, valid := bindCursor(ctx, req.pageRequest, "", false)
+	if !valid {
+		return
+	}
+
+	arg := db.ListPromotionsParams{
+		AfterID: sql.NullInt64{Int64: cursor.ID, Valid: cursor.ID != 0},
+	}
+	arg.Limit, arg.Offset = req.query()
+
+	promos, err := server.store.ListPromotions(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]promotionResponse, 0, len(promos))
+	for _, promo := range promos {
+		rsp = append(rsp, buildPromotionResponse(promo))
+	}
+
+	if req.offsetPaged() {
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	page := listResponse{Data: rsp}
+	if req.hasNextPage(len(rsp)) {
+		rsp = rsp[:req.pageLimit()]
+		page.Data = rsp
+		page.NextCursor = pageCursor{ID: rsp[len(rsp)-1].ID}.encode()
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// promotionErrorStatus is the status answered for an error of the promotion code of an order,
+// zero when the error does not come from the promotion
+func promotionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrPromotionNotFound),
+		errors.Is(err, db.ErrPromotionNotActive),
+		errors.Is(err, db.ErrPromotionNotApplicable):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrPromotionExhausted),
+		errors.Is(err, db.ErrPromotionUserLimit):
+		return http.StatusConflict
+	}
+	return 0
+}
+
+// listOrderDiscounts handler to list the discount lines of an order, an order of another user is not found as on getOrder
+func (server *Server) listOrderDiscounts(ctx *gin.Context) {
+	var req getOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload, valid := authorizedUser(ctx, 0)
+	if !valid {
+		return
+	}
+
+	order, err := server.store.GetPokemonOrderData(ctx, req.ID)
+	if err == nil && !orderVisible(authPayload, order) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	discounts, err := server.store.ListOrderDiscounts(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, discounts)
+}
@@ -0,0 +1,435 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func mockRandomPromotion() db.Promotion {
+	return db.Promotion{
+		ID:            util.RandomInt(1, 200),
+		Code:          "ZUBAT20",
+		DiscountType:  db.PromotionPercentage,
+		DiscountValue: 20,
+		ProductID:     sql.NullInt64{Int64: util.RandomInt(1, 200), Valid: true},
+		StartsAt:      time.Now(),
+		CreatedBy:     util.RandomUser(),
+	}
+}
+
+func TestCreatePromotionAPI(t *testing.T) {
+	promo := mockRandomPromotion()
+	username := util.RandomUser()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"code":           "zubat20",
+				"discount_type":  promo.DiscountType,
+				"discount_value": promo.DiscountValue,
+				"product_id":     promo.ProductID.Int64,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePromotion(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePromotionParams) (db.Promotion, error) {
+						require.Equal(t, promo.Code, arg.Code)
+						require.Equal(t, promo.ProductID, arg.ProductID)
+						require.False(t, arg.Currency.Valid)
+						require.False(t, arg.MaxRedemptions.Valid)
+						require.Equal(t, username, arg.CreatedBy)
+						require.WithinDuration(t, time.Now(), arg.StartsAt, time.Second)
+						return promo, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotPromo promotionResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotPromo)
+				require.NoError(t, err)
+				require.Equal(t, promo.ID, gotPromo.ID)
+				require.Equal(t, promo.ProductID.Int64, gotPromo.ProductID)
+				require.Nil(t, gotPromo.EndsAt)
+			},
+		},
+		{
+			name: "FixedAmountDefaultCurrency",
+			body: gin.H{
+				"code":                     "FIVEOFF",
+				"discount_type":            db.PromotionFixedAmount,
+				"discount_value":           500,
+				"poke_type":                "poison",
+				"user_role":                "GRUNT",
+				"max_redemptions":          100,
+				"max_redemptions_per_user": 1,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePromotion(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePromotionParams) (db.Promotion, error) {
+						require.Equal(t, sql.NullString{String: db.DefaultCurrency, Valid: true}, arg.Currency)
+						require.Equal(t, sql.NullString{String: "poison", Valid: true}, arg.PokeType)
+						require.Equal(t, sql.NullString{String: "GRUNT", Valid: true}, arg.UserRole)
+						require.Equal(t, sql.NullInt32{Int32: 100, Valid: true}, arg.MaxRedemptions)
+						require.Equal(t, sql.NullInt32{Int32: 1, Valid: true}, arg.MaxRedemptionsPerUser)
+						return promo, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PercentageOver100",
+			body: gin.H{
+				"code":           "FREE",
+				"discount_type":  db.PromotionPercentage,
+				"discount_value": 101,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePromotion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PercentageWithCurrency",
+			body: gin.H{
+				"code":           "TENOFF",
+				"discount_type":  db.PromotionPercentage,
+				"discount_value": 10,
+				"currency":       "USD",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePromotion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EndsBeforeStart",
+			body: gin.H{
+				"code":           "TENOFF",
+				"discount_type":  db.PromotionPercentage,
+				"discount_value": 10,
+				"starts_at":      time.Now().Add(time.Hour),
+				"ends_at":        time.Now(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePromotion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDiscountType",
+			body: gin.H{
+				"code":           "TENOFF",
+				"discount_type":  "buy_one_get_one",
+				"discount_value": 10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePromotion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DuplicateCode",
+			body: gin.H{
+				"code":           promo.Code,
+				"discount_type":  db.PromotionPercentage,
+				"discount_value": 10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePromotion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Promotion{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/promotion", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.RandomInt(1, 300), "LEAD", time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetPromotionAPI(t *testing.T) {
+	promo := mockRandomPromotion()
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: "LEAD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPromotion(gomock.Any(), gomock.Eq(promo.ID)).
+					Times(1).
+					Return(promo, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotPromo promotionResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotPromo)
+				require.NoError(t, err)
+				require.Equal(t, promo.Code, gotPromo.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: "LEAD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPromotion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Promotion{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ForbiddenForGrunt",
+			role: "GRUNT",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPromotion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/promotion/%d", promo.ID), nil)
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), util.RandomInt(1, 300), tc.role, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOrderPromotionCodeAPI(t *testing.T) {
+	order := mockRandomOrder()
+
+	testCases := []struct {
+		name          string
+		path          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OrderWithCode",
+			path: "/order",
+			body: gin.H{"product_id": order.ProductID.Int64, "quantity": order.Quantity, "promotion_code": "zubat20"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					OrderTx(gomock.Any(), gomock.Eq(db.OrderTxParams{
+						UserID:        order.UserID,
+						ProductID:     order.ProductID.Int64,
+						Quantity:      order.Quantity,
+						PromotionCode: "ZUBAT20",
+					})).
+					Times(1).
+					Return(db.OrderTxResult{Order: order}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OrderUnknownCode",
+			path: "/order",
+			body: gin.H{"product_id": order.ProductID.Int64, "quantity": order.Quantity, "promotion_code": "NOPE"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					OrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OrderTxResult{}, fmt.Errorf("%w: NOPE", db.ErrPromotionNotFound))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OrderInvalidCode",
+			path: "/order",
+			body: gin.H{"product_id": order.ProductID.Int64, "quantity": order.Quantity, "promotion_code": "20% OFF"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					OrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OrderExhaustedCode",
+			path: "/order",
+			body: gin.H{"product_id": order.ProductID.Int64, "quantity": order.Quantity, "promotion_code": "ZUBAT20"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					OrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OrderTxResult{}, fmt.Errorf("%w: ZUBAT20", db.ErrPromotionExhausted))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "CheckoutWithCode",
+			path: "/cart/checkout",
+			body: gin.H{"promotion_code": "ZUBAT20"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Eq(db.CheckoutTxParams{
+						UserID:        order.UserID,
+						PromotionCode: "ZUBAT20",
+					})).
+					Times(1).
+					Return(db.CheckoutTxResult{Order: order}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "CheckoutUserLimit",
+			path: "/cart/checkout",
+			body: gin.H{"promotion_code": "ZUBAT20"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CheckoutTxResult{}, fmt.Errorf("%w: ZUBAT20", db.ErrPromotionUserLimit))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "CheckoutNotApplicable",
+			path: "/cart/checkout",
+			body: gin.H{"promotion_code": "ZUBAT20"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CheckoutTxResult{}, fmt.Errorf("%w: ZUBAT20", db.ErrPromotionNotApplicable))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), order.UserID, "GRUNT", time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+)
+
+// refundOrderRequest represent request payload for giving back part of an order line,
+// the item is only required when the order has several lines
+type refundOrderRequest struct {
+	ItemID   int64  `json:"item_id" binding:"min=0"`
+	Quantity int32  `json:"quantity" binding:"required,min=1"`
+	Reason   string `json:"reason" binding:"required,max=255"`
+}
+
+// refundOrder handler to refund a quantity of an order line, the quantity is restocked
+// and the net total of the order drops, a pending order is partially cancelled the same way
+func (server *Server) refundOrder(ctx *gin.Context) {
+	var req getOrderRequest
+	var refundReq refundOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&refundReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload, valid := authorizedUser(ctx, 0)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.RefundOrderTx(ctx, db.RefundOrderTxParams{
+		OrderID:    req.ID,
+		ItemID:     refundReq.ItemID,
+		Quantity:   refundReq.Quantity,
+		Reason:     refundReq.Reason,
+		RefundedBy: authPayload.Username,
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrRefundItemRequired):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrRefundExceedsQuantity),
+			errors.Is(err, db.ErrInvalidOrderTransition):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// listOrderRefunds handler to list the refunds made on an order
+func (server *Server) listOrderRefunds(ctx *gin.Context) {
+	var req getOrderRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, valid := authorizedUser(ctx, 0)
+	if !valid {
+		return
+	}
+
+	order, err := server.store.GetPokemonOrderData(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	refunds, err := server.store.ListOrderRefunds(ctx, order.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, refunds)
+}
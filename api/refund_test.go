@@ -0,0 +1,255 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundOrderAPI(t *testing.T) {
+	order := mockRandomOrder()
+	username := util.RandomUser()
+	refund := db.OrderRefund{
+		ID:          util.RandomInt(1, 200),
+		OrderID:     order.ID,
+		OrderItemID: util.RandomInt(1, 200),
+		ProductID:   order.ProductID.Int64,
+		Quantity:    1,
+		Amount:      order.TotalPrice / int64(order.Quantity),
+		Reason:      util.RandomString(12),
+		OrderStatus: db.OrderStatusPaid,
+		RefundedBy:  username,
+	}
+	refundPath := fmt.Sprintf("/order/%d/refund", order.ID)
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Refund",
+			method: http.MethodPost,
+			path:   refundPath,
+			body:   gin.H{"item_id": refund.OrderItemID, "quantity": refund.Quantity, "reason": refund.Reason},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.RefundOrderTxParams{
+					OrderID:    order.ID,
+					ItemID:     refund.OrderItemID,
+					Quantity:   refund.Quantity,
+					Reason:     refund.Reason,
+					RefundedBy: username,
+				}
+				store.EXPECT().
+					RefundOrderTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.RefundOrderTxResult{Order: order, Refund: refund}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotResult db.RefundOrderTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotResult)
+				require.NoError(t, err)
+				require.Equal(t, refund.ID, gotResult.Refund.ID)
+				require.Equal(t, username, gotResult.Refund.RefundedBy)
+			},
+		},
+		{
+			name:   "MissingReason",
+			method: http.MethodPost,
+			path:   refundPath,
+			body:   gin.H{"quantity": 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RefundOrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidQuantity",
+			method: http.MethodPost,
+			path:   refundPath,
+			body:   gin.H{"quantity": 0, "reason": refund.Reason},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RefundOrderTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "ItemRequired",
+			method: http.MethodPost,
+			path:   refundPath,
+			body:   gin.H{"quantity": 1, "reason": refund.Reason},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RefundOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RefundOrderTxResult{}, db.ErrRefundItemRequired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "ExceedsQuantity",
+			method: http.MethodPost,
+			path:   refundPath,
+			body:   gin.H{"quantity": 1, "reason": refund.Reason},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RefundOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RefundOrderTxResult{}, fmt.Errorf("%w: 0 left, 1 requested", db.ErrRefundExceedsQuantity))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "ClosedOrder",
+			method: http.MethodPost,
+			path:   refundPath,
+			body:   gin.H{"quantity": 1, "reason": refund.Reason},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RefundOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RefundOrderTxResult{}, fmt.Errorf("%w: cancelled to refunded", db.ErrInvalidOrderTransition))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "OrderNotFound",
+			method: http.MethodPost,
+			path:   refundPath,
+			body:   gin.H{"quantity": 1, "reason": refund.Reason},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RefundOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RefundOrderTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "ListRefunds",
+			method: http.MethodGet,
+			path:   fmt.Sprintf("/order/%d/refunds", order.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPokemonOrderData(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(order, nil)
+				store.EXPECT().
+					ListOrderRefunds(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return([]db.OrderRefund{refund}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotRefunds []db.OrderRefund
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotRefunds)
+				require.NoError(t, err)
+				require.Len(t, gotRefunds, 1)
+				require.Equal(t, refund.ID, gotRefunds[0].ID)
+			},
+		},
+		{
+			name:   "ListRefundsOrderNotFound",
+			method: http.MethodGet,
+			path:   fmt.Sprintf("/order/%d/refunds", order.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPokemonOrderData(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PokeOrder{}, sql.ErrNoRows)
+				store.EXPECT().
+					ListOrderRefunds(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var data []byte
+			if tc.body != nil {
+				var err error
+				data, err = json.Marshal(tc.body)
+				require.NoError(t, err)
+			}
+
+			request, err := http.NewRequest(tc.method, tc.path, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, order.UserID, "LEAD", time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRefundOrderForbiddenForGrunt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		RefundOrderTx(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"quantity": 1, "reason": "damaged"})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/order/1/refund", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), util.RandomInt(1, 300), "GRUNT", time.Minute)
+	server.route.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+)
+
+// createReservationRequest represent request payload for holding stock of a product
+type createReservationRequest struct {
+	UserID    int64 `json:"user_id"`
+	ProductID int64 `json:"product_id" binding:"required,min=1"`
+	Quantity  int32 `json:"quantity" binding:"required,min=1"`
+}
+
+// createReservation handler to hold stock of a product for the selected user until the reservation expires
+func (server *Server) createReservation(ctx *gin.Context) {
+	var req createReservationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload, valid := authorizedUser(ctx, req.UserID)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.ReserveStockTx(ctx, db.ReserveStockTxParams{
+		UserID:    authPayload.UserID,
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		ExpiresAt: time.Now().Add(server.config.ReservationDuration),
+	})
+	if err != nil {
+		reservationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// reservationRequest represent id of the reservation for binding parameter
+type reservationRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getReservation handler to get a reservation of the selected user
+func (server *Server) getReservation(ctx *gin.Context) {
+	var req reservationRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	userID, valid := bindSelectedUser(ctx)
+	if !valid {
+		return
+	}
+
+	reservation, err := server.store.GetStockReservation(ctx, req.ID)
+	if err == nil && reservation.UserID != userID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		reservationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+// convertReservation handler to turn an active reservation into a pending order
+func (server *Server) convertReservation(ctx *gin.Context) {
+	var req reservationRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	userID, valid := bindSelectedUser(ctx)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.ConvertReservationTx(ctx, db.ReservationTxParams{
+		ID:     req.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		reservationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// releaseReservation handler to give the stock of an active reservation back before it expires
+func (server *Server) releaseReservation(ctx *gin.Context) {
+	var req reservationRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	userID, valid := bindSelectedUser(ctx)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.ReleaseReservationTx(ctx, db.ReservationTxParams{
+		ID:     req.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		reservationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// reservationError answers the error of a reservation transaction,
+// missing rows are 404 and a reservation which cannot move anymore is 409
+func reservationError(ctx *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrInsufficientStock),
+		errors.Is(err, db.ErrReservationNotActive),
+		errors.Is(err, db.ErrReservationExpired):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gunhachi/poke-blackmarket/db/mock"
+	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservationAPI(t *testing.T) {
+	reservation := mockRandomReservation()
+	order := mockRandomOrder()
+	order.UserID = reservation.UserID
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			path:   "/reservation",
+			body:   gin.H{"product_id": reservation.ProductID, "quantity": reservation.Quantity},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveStockTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ReserveStockTxParams) (db.ReservationTxResult, error) {
+						require.Equal(t, reservation.UserID, arg.UserID)
+						require.Equal(t, reservation.ProductID, arg.ProductID)
+						require.Equal(t, reservation.Quantity, arg.Quantity)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+						return db.ReservationTxResult{Reservation: reservation}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotResult db.ReservationTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotResult)
+				require.NoError(t, err)
+				require.Equal(t, reservation.ID, gotResult.Reservation.ID)
+			},
+		},
+		{
+			name:   "CreateInsufficientStock",
+			method: http.MethodPost,
+			path:   "/reservation",
+			body:   gin.H{"product_id": reservation.ProductID, "quantity": reservation.Quantity},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveStockTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReservationTxResult{}, db.ErrInsufficientStock)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "CreateUnknownProduct",
+			method: http.MethodPost,
+			path:   "/reservation",
+			body:   gin.H{"product_id": reservation.ProductID, "quantity": reservation.Quantity},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveStockTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReservationTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "CreateInvalidQuantity",
+			method: http.MethodPost,
+			path:   "/reservation",
+			body:   gin.H{"product_id": reservation.ProductID, "quantity": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveStockTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "Get",
+			method: http.MethodGet,
+			path:   fmt.Sprintf("/reservation/%d", reservation.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetStockReservation(gomock.Any(), gomock.Eq(reservation.ID)).
+					Times(1).
+					Return(reservation, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "GetOtherUser",
+			method: http.MethodGet,
+			path:   fmt.Sprintf("/reservation/%d", reservation.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				other := reservation
+				other.UserID++
+				store.EXPECT().
+					GetStockReservation(gomock.Any(), gomock.Eq(reservation.ID)).
+					Times(1).
+					Return(other, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "Convert",
+			method: http.MethodPost,
+			path:   fmt.Sprintf("/reservation/%d/convert", reservation.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				converted := reservation
+				converted.Status = db.ReservationStatusConverted
+				converted.OrderID = sql.NullInt64{Int64: order.ID, Valid: true}
+				store.EXPECT().
+					ConvertReservationTx(gomock.Any(), gomock.Eq(db.ReservationTxParams{
+						ID:     reservation.ID,
+						UserID: reservation.UserID,
+					})).
+					Times(1).
+					Return(db.ConvertReservationTxResult{Order: order, Reservation: converted}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotResult db.ConvertReservationTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotResult)
+				require.NoError(t, err)
+				require.Equal(t, order.ID, gotResult.Order.ID)
+				require.Equal(t, db.ReservationStatusConverted, gotResult.Reservation.Status)
+			},
+		},
+		{
+			name:   "ConvertExpired",
+			method: http.MethodPost,
+			path:   fmt.Sprintf("/reservation/%d/convert", reservation.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConvertReservationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConvertReservationTxResult{}, db.ErrReservationExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "Release",
+			method: http.MethodDelete,
+			path:   fmt.Sprintf("/reservation/%d", reservation.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReleaseReservationTx(gomock.Any(), gomock.Eq(db.ReservationTxParams{
+						ID:     reservation.ID,
+						UserID: reservation.UserID,
+					})).
+					Times(1).
+					Return(db.ReservationTxResult{Reservation: reservation}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ReleaseTwice",
+			method: http.MethodDelete,
+			path:   fmt.Sprintf("/reservation/%d", reservation.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				err := fmt.Errorf("%w: %s", db.ErrReservationNotActive, db.ReservationStatusReleased)
+				store.EXPECT().
+					ReleaseReservationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReservationTxResult{}, err)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "ReleaseInternalError",
+			method: http.MethodDelete,
+			path:   fmt.Sprintf("/reservation/%d", reservation.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReleaseReservationTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReservationTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var data []byte
+			if tc.body != nil {
+				var err error
+				data, err = json.Marshal(tc.body)
+				require.NoError(t, err)
+			}
+
+			request, err := http.NewRequest(tc.method, tc.path, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, util.RandomUser(), reservation.UserID, "GRUNT", time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// mockRandomReservation create random active reservation data
+func mockRandomReservation() db.StockReservation {
+	return db.StockReservation{
+		ID:        util.RandomInt(1, 200),
+		UserID:    util.RandomInt(1, 300),
+		ProductID: util.RandomInt(1, 300),
+		Quantity:  int32(util.RandomInt(1, 10)),
+		Status:    db.ReservationStatusActive,
+		ExpiresAt: time.Now().Add(time.Minute).UTC().Truncate(time.Second),
+	}
+}
@@ -1,32 +1,76 @@
 package api
 
 import (
+	"expvar"
 	"fmt"
+	"io/ioutil"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
+	"github.com/gunhachi/poke-blackmarket/notify"
+	"github.com/gunhachi/poke-blackmarket/policy"
 	"github.com/gunhachi/poke-blackmarket/token"
 	"github.com/gunhachi/poke-blackmarket/util"
 )
 
+const (
+	tokenTypePaseto       = "paseto"
+	tokenTypePasetoPublic = "paseto-public"
+	tokenTypeJWT          = "jwt"
+)
+
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
-	route      *gin.Engine
+	config         util.Config
+	store          db.Store
+	tokenMaker     token.Maker
+	revocations    token.RevocationStore
+	policy         *policy.Policy
+	hasher         util.PasswordHasher
+	passwordPolicy *util.PasswordPolicy
+	notifier       notify.Notifier
+	route          *gin.Engine
+
+	dummyPasswordOnce sync.Once
+	dummyPassword     string
 }
 
 // NewServer creates a new HTTP server and setup routes
-func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymKey)
+func NewServer(config util.Config, store db.Store, revocations token.RevocationStore) (*Server, error) {
+	tokenMaker, err := newTokenMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	enforcer, err := policy.Load(config.PolicyPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load policy: %w", err)
+	}
+
+	hasher, err := util.NewPasswordHasher(config.PasswordHasher)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
+	passwordPolicy, err := util.NewPasswordPolicy(config.PasswordMinLength, config.BreachedPasswordPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load password policy: %w", err)
+	}
+
+	notifier, err := notify.New(config.NotifierType, config.NotifierPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create notifier: %w", err)
+	}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:         config,
+		store:          store,
+		tokenMaker:     tokenMaker,
+		revocations:    revocations,
+		policy:         enforcer,
+		hasher:         hasher,
+		passwordPolicy: passwordPolicy,
+		notifier:       notifier,
 	}
 
 	server.setupRouter()
@@ -39,9 +83,39 @@ func (server *Server) setupRouter() {
 
 	router.POST("/account", server.createAccountLog)
 	router.POST("/account/login", server.loginAccount)
+	router.POST("/account/login/mfa", server.verifyLoginTotp)
+	router.POST("/account/password/forgot", server.forgotPassword)
+	router.POST("/account/password/reset", server.resetPassword)
+	router.POST("/account/token/renew", server.renewAccessToken)
 	router.GET("/pokemon-api/:name", server.getDataPokemonApi)
-
-	authRoute := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	router.GET("/.well-known/paseto-keys", server.listPublicKeys)
+
+	authRoute := router.Group("/").Use(
+		authMiddleware(server.tokenMaker, server.revocations, server.store),
+		policyMiddleware(server.policy),
+	)
+
+	// the mutating order routes replay their response when sent again with the same Idempotency-Key
+	idempotent := idempotencyMiddleware(server.store, server.config.IdempotencyKeyTTL)
+
+	// the routes minting sessions or managing the password, two-factor and api keys are not open to api keys
+	sessionOnly := sessionOnlyMiddleware()
+
+	authRoute.PUT("/account/password", sessionOnly, server.updatePassword)
+	authRoute.POST("/account/logout", server.logoutAccount)
+	authRoute.POST("/account/user/select", sessionOnly, server.selectUser)
+	authRoute.POST("/account/sessions/revoke", server.revokeAccountSessions)
+	authRoute.POST("/account/disable", server.disableAccount)
+	authRoute.POST("/account/enable", server.enableAccount)
+	authRoute.POST("/account/mfa/enroll", sessionOnly, server.enrollTotp)
+	authRoute.POST("/account/mfa/confirm", sessionOnly, server.confirmTotp)
+	authRoute.POST("/account/mfa/disable", sessionOnly, server.disableTotp)
+	authRoute.GET("/token/keys", server.listTokenKeys)
+	authRoute.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+
+	authRoute.POST("/account/api-keys", sessionOnly, server.createApiKey)
+	authRoute.GET("/account/api-keys", sessionOnly, server.listApiKeys)
+	authRoute.DELETE("/account/api-keys/:id", sessionOnly, server.revokeApiKey)
 
 	authRoute.GET("/user", server.listUser)
 	authRoute.GET("/user/:id", server.getUser)
@@ -52,18 +126,79 @@ func (server *Server) setupRouter() {
 	authRoute.GET("/pokemon/:id", server.getPokemon)
 	authRoute.PUT("/pokemon/:id", server.updatePokemon)
 
-	authRoute.POST("/order", server.createOrder)
+	authRoute.POST("/order", idempotent, server.createOrder)
 	authRoute.GET("/order/:id", server.getOrder)
-	authRoute.DELETE("/order/:id", server.cancelOrder)
+	authRoute.DELETE("/order/:id", idempotent, server.cancelOrder)
+	authRoute.POST("/order/:id/pay", idempotent, server.payOrder)
+	authRoute.POST("/order/:id/fulfill", idempotent, server.fulfillOrder)
+	authRoute.GET("/order/:id/items", server.listOrderItems)
+	authRoute.POST("/order/:id/refund", idempotent, server.refundOrder)
+	authRoute.GET("/order/:id/refunds", server.listOrderRefunds)
+	authRoute.GET("/order/:id/discounts", server.listOrderDiscounts)
+
+	authRoute.POST("/reservation", idempotent, server.createReservation)
+	authRoute.GET("/reservation/:id", server.getReservation)
+	authRoute.POST("/reservation/:id/convert", idempotent, server.convertReservation)
+	authRoute.DELETE("/reservation/:id", server.releaseReservation)
+
+	authRoute.GET("/cart", server.getCart)
+	authRoute.POST("/cart/items", server.addCartItem)
+	authRoute.PUT("/cart/items/:id", server.updateCartItem)
+	authRoute.DELETE("/cart/items/:id", server.removeCartItem)
+	authRoute.POST("/cart/checkout", idempotent, server.checkoutCart)
+
+	authRoute.POST("/promotion", server.createPromotion)
+	authRoute.GET("/promotion", server.listPromotions)
+	authRoute.GET("/promotion/:id", server.getPromotion)
 
 	authRoute.GET("/order", server.listOrder)
 	authRoute.GET("/order-detailed", server.listOrderDetailed)
 	authRoute.PUT("/user/:id", server.updateUser)
+	authRoute.DELETE("/user/:id", server.deleteUser)
 
 	server.route = router
 
 }
 
+// newTokenMaker creates the token maker chosen by TOKEN_TYPE, paseto is used by default
+func newTokenMaker(config util.Config) (token.Maker, error) {
+	switch config.TokenType {
+	case "", tokenTypePaseto:
+		if config.TokenKeyringPath == "" {
+			return token.NewPasetoKeyringMaker(token.NewKeyring(config.TokenKeyID, config.TokenSymKey))
+		}
+
+		keyring, err := token.LoadKeyring(config.TokenKeyringPath)
+		if err != nil {
+			return nil, err
+		}
+		return token.NewPasetoKeyringMaker(keyring)
+	case tokenTypePasetoPublic:
+		privateKeyPEM, err := ioutil.ReadFile(config.TokenPrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read token private key: %w", err)
+		}
+
+		privateKey, err := token.ParseEd25519PrivateKeyPEM(privateKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		return token.NewPasetoPublicMaker(config.TokenKeyID, privateKey)
+	case tokenTypeJWT:
+		if config.TokenAlgorithm == "" || config.TokenAlgorithm == "HS256" {
+			return token.NewJWTMaker(config.TokenSymKey)
+		}
+
+		privateKeyPEM, err := ioutil.ReadFile(config.TokenPrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read token private key: %w", err)
+		}
+		return token.NewAsymmetricJWTMaker(config.TokenAlgorithm, privateKeyPEM)
+	default:
+		return nil, fmt.Errorf("unsupported token type %s", config.TokenType)
+	}
+}
+
 // Start run the http server
 func (server *Server) Start(address string) error {
 	return server.route.Run(address)
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/token"
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenMaker(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "token.pem")
+	err = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+
+	keyringPath := filepath.Join(t.TempDir(), "keyring.json")
+	keyring := `{"active_key_id": "2026-10", "keys": [{"id": "2026-10", "key": "` + util.RandomString(32) + `"}]}`
+	err = ioutil.WriteFile(keyringPath, []byte(keyring), 0600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		config    util.Config
+		checkType func(t *testing.T, maker token.Maker, err error)
+	}{
+		{
+			name:   "DefaultPaseto",
+			config: util.Config{TokenSymKey: util.RandomString(32)},
+			checkType: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.PasetoMaker{}, maker)
+			},
+		},
+		{
+			name:   "PasetoKeyring",
+			config: util.Config{TokenKeyringPath: keyringPath},
+			checkType: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.PasetoMaker{}, maker)
+
+				keys := maker.(token.KeyReporter).Keys()
+				require.Len(t, keys, 1)
+				require.Equal(t, "2026-10", keys[0].ID)
+			},
+		},
+		{
+			name:   "JWTHS256",
+			config: util.Config{TokenType: tokenTypeJWT, TokenSymKey: util.RandomString(32)},
+			checkType: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.JWTMaker{}, maker)
+			},
+		},
+		{
+			name:   "JWTEdDSA",
+			config: util.Config{TokenType: tokenTypeJWT, TokenAlgorithm: "EdDSA", TokenPrivateKeyPath: keyPath},
+			checkType: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.JWTMaker{}, maker)
+			},
+		},
+		{
+			name:   "PasetoPublic",
+			config: util.Config{TokenType: tokenTypePasetoPublic, TokenKeyID: "2026-10", TokenPrivateKeyPath: keyPath},
+			checkType: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.PasetoPublicMaker{}, maker)
+
+				keys := maker.(token.PublicKeyPublisher).PublicKeys()
+				require.Len(t, keys, 1)
+				require.Equal(t, "2026-10", keys[0].KeyID)
+			},
+		},
+		{
+			name:   "MissingPrivateKey",
+			config: util.Config{TokenType: tokenTypeJWT, TokenAlgorithm: "RS256", TokenPrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem")},
+			checkType: func(t *testing.T, maker token.Maker, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name:   "UnsupportedType",
+			config: util.Config{TokenType: "unknown", TokenSymKey: util.RandomString(32)},
+			checkType: func(t *testing.T, maker token.Maker, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := newTokenMaker(tc.config)
+			tc.checkType(t, maker, err)
+		})
+	}
+}
+
+func TestDebugVars(t *testing.T) {
+	testCases := []struct {
+		name          string
+		role          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Admin",
+			role: "ADMIN",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"db_tx"`)
+			},
+		},
+		{
+			name: "NotAdmin",
+			role: "LEAD",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/debug/vars", nil)
+			require.NoError(t, err)
+
+			addUserAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", 1, tc.role, time.Minute)
+			server.route.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
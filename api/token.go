@@ -0,0 +1,267 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gunhachi/poke-blackmarket/token"
+)
+
+var (
+	errNotAccessToken  = errors.New("token is not an access token")
+	errNotRefreshToken = errors.New("token is not a refresh token")
+)
+
+// renewAccessTokenRequest represent param to renew the access token
+type renewAccessTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// renewAccessTokenResponse represent response of a renewed access token
+type renewAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+// renewAccessToken handler to issue a new access token based on a valid session
+func (server *Server) renewAccessToken(ctx *gin.Context) {
+	var req renewAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if refreshPayload.Kind != token.RefreshToken {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errNotRefreshToken))
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if session.IsBlocked {
+		err := errors.New("blocked session")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if session.Username != refreshPayload.Username {
+		err := errors.New("incorrect session user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if session.RefreshToken != req.RefreshToken {
+		err := errors.New("mismatched session token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		err := errors.New("expired session")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if session.UserAgent != ctx.Request.UserAgent() || session.ClientIp != ctx.ClientIP() {
+		err := errors.New("session used from a different client")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		refreshPayload.Username,
+		refreshPayload.UserID,
+		refreshPayload.Role,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := renewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// logoutAccountRequest represent param to logout, refresh token is optional
+type logoutAccountRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// logoutAccount handler to revoke the access token in use, the refresh token given is revoked and its session blocked
+func (server *Server) logoutAccount(ctx *gin.Context) {
+	var req logoutAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if req.RefreshToken != "" {
+		refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		if refreshPayload.Kind != token.RefreshToken {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errNotRefreshToken))
+			return
+		}
+
+		if refreshPayload.Username != authPayload.Username {
+			err := errors.New("refresh token doesn't belong to the authenticated user")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		_, err = server.store.BlockSession(ctx, refreshPayload.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		err = server.revocations.RevokeToken(ctx, refreshPayload.ID, refreshPayload.Username, refreshPayload.ExpiredAt)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	err := server.revocations.RevokeToken(ctx, authPayload.ID, authPayload.Username, authPayload.ExpiredAt)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"revoked": authPayload.ID})
+}
+
+// revokeAccountSessionsRequest represent param to revoke every session of an account
+type revokeAccountSessionsRequest struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username" binding:"required,alphanum"`
+}
+
+// revokeAccountSessions handler for LEAD user to revoke every session and token of an account
+func (server *Server) revokeAccountSessions(ctx *gin.Context) {
+	var req revokeAccountSessionsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, valid := authorizedUser(ctx, req.UserID)
+	if !valid {
+		return
+	}
+
+	account, err := server.store.GetAccountLog(ctx, req.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.revokeAccount(ctx, account.Username, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"revoked": account.Username})
+}
+
+// revokeAccount blocks every session of the account and rejects the tokens issued before the given time
+func (server *Server) revokeAccount(ctx *gin.Context, username string, before time.Time) error {
+	err := server.store.BlockAccountSessions(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	return server.revocations.RevokeAccount(ctx, username, before)
+}
+
+// listTokenKeysRequest represent param to list the token keys, user_id is the corresponding LEAD user id
+type listTokenKeysRequest struct {
+	UserID int64 `form:"user_id"`
+}
+
+// listTokenKeysResponse represent the key ids used to sign and verify tokens
+type listTokenKeysResponse struct {
+	Keys []token.KeyInfo `json:"keys"`
+}
+
+// listTokenKeys handler for LEAD user to report the key ids in use by the token maker
+func (server *Server) listTokenKeys(ctx *gin.Context) {
+	var req listTokenKeysRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, valid := authorizedUser(ctx, req.UserID)
+	if !valid {
+		return
+	}
+
+	rsp := listTokenKeysResponse{
+		Keys: []token.KeyInfo{},
+	}
+	if reporter, ok := server.tokenMaker.(token.KeyReporter); ok {
+		rsp.Keys = reporter.Keys()
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// listPublicKeysResponse represent the public keys able to verify the tokens
+type listPublicKeysResponse struct {
+	Keys []token.PublicKeyInfo `json:"keys"`
+}
+
+// listPublicKeys handler to publish the public keys so other services can verify tokens offline
+func (server *Server) listPublicKeys(ctx *gin.Context) {
+	publisher, ok := server.tokenMaker.(token.PublicKeyPublisher)
+	if !ok {
+		err := errors.New("token maker doesn't use public keys")
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	rsp := listPublicKeysResponse{
+		Keys: publisher.PublicKeys(),
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
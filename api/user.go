@@ -3,8 +3,10 @@ package api
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
@@ -12,8 +14,17 @@ import (
 	"github.com/lib/pq"
 )
 
+// roleAdmin is the only role able to manage every user and account
+const roleAdmin = "ADMIN"
+
+// roleLead is the role of the users running the market, along with ADMIN they see the orders of every user
+const roleLead = "LEAD"
+
+// roleGrunt is the only role a user is created with, unless the session acts as an ADMIN user
+const roleGrunt = "GRUNT"
+
 type createUserRequest struct {
-	UserRole string `json:"user_role" binding:"required,oneof= LEAD GRUNT"`
+	UserRole string `json:"user_role" binding:"required,oneof= LEAD GRUNT ADMIN"`
 }
 
 func (server *Server) createUser(ctx *gin.Context) {
@@ -23,7 +34,15 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	// the account named by ADMIN_USERNAME creates the first ADMIN user, the other roles above GRUNT are granted by an ADMIN user
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	bootstrapAdmin := req.UserRole == roleAdmin && server.config.AdminUsername != "" && authPayload.Username == server.config.AdminUsername
+	if req.UserRole != roleGrunt && authPayload.Role != roleAdmin && !bootstrapAdmin {
+		err := fmt.Errorf("only %s users create a %s user", roleAdmin, req.UserRole)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
 	arg := db.CreateUserAccountParams{
 		UserName: authPayload.Username,
 		UserRole: req.UserRole,
@@ -65,9 +84,9 @@ func (server *Server) getUser(ctx *gin.Context) {
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if user.UserName != authPayload.Username {
+	if user.UserName != authPayload.Username && authPayload.Role != roleAdmin {
 		err := errors.New("user dont belong to authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
 		return
 	}
 
@@ -76,8 +95,7 @@ func (server *Server) getUser(ctx *gin.Context) {
 }
 
 type listUserRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	pageRequest
 }
 
 func (server *Server) listUser(ctx *gin.Context) {
@@ -87,23 +105,57 @@ func (server *Server) listUser(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListUserAccountParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	cursor, valid := bindCursor(ctx, req.pageRequest, "", false)
+	if !valid {
+		return
 	}
 
-	users, err := server.store.ListUserAccount(ctx, arg)
+	var users []db.User
+	var err error
+	afterID := sql.NullInt64{Int64: cursor.ID, Valid: cursor.ID != 0}
+	limit, offset := req.query()
+
+	// only admin is able to see every user, the others only see their own
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Role == roleAdmin {
+		arg := db.ListUserAccountParams{
+			AfterID: afterID,
+			Limit:   limit,
+			Offset:  offset,
+		}
+		users, err = server.store.ListUserAccount(ctx, arg)
+	} else {
+		arg := db.ListUserAccountByNameParams{
+			UserName: authPayload.Username,
+			AfterID:  afterID,
+			Limit:    limit,
+			Offset:   offset,
+		}
+		users, err = server.store.ListUserAccountByName(ctx, arg)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, users)
+	if req.offsetPaged() {
+		ctx.JSON(http.StatusOK, users)
+		return
+	}
+
+	resp := listResponse{Data: users}
+	if req.hasNextPage(len(users)) {
+		users = users[:req.pageLimit()]
+		resp.Data = users
+		resp.NextCursor = pageCursor{ID: users[len(users)-1].ID}.encode()
+	}
+
+	ctx.JSON(http.StatusOK, resp)
 
 }
 
 type updateUserRequestRole struct {
-	UserRole string `json:"user_role" binding:"required,oneof= LEAD GRUNT"`
+	UserRole string `json:"user_role" binding:"required,oneof= LEAD GRUNT ADMIN"`
 }
 
 func (server *Server) updateUser(ctx *gin.Context) {
@@ -125,6 +177,17 @@ func (server *Server) updateUser(ctx *gin.Context) {
 	}
 
 	user, err := server.store.UpdateUserAccountRole(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// tokens carry the role, so they have to be reissued after a role change
+	err = server.revokeAccount(ctx, user.UserName, time.Now())
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -133,3 +196,92 @@ func (server *Server) updateUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, user)
 
 }
+
+// deleteUser handler for admin to delete a user, the tokens of its account have to be reissued afterward
+func (server *Server) deleteUser(ctx *gin.Context) {
+	var req getUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserAccount(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteUserAccount(ctx, user.ID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.revokeAccount(ctx, user.UserName, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"deleted": user.ID})
+}
+
+// selectUserRequest represent param to choose the user a session acts as
+type selectUserRequest struct {
+	UserID int64 `json:"user_id" binding:"required,min=1"`
+}
+
+// responseSelectUser represent the tokens of the new session acting as the selected user
+type responseSelectUser struct {
+	responseSession
+	User db.User `json:"user"`
+}
+
+// selectUser handler to issue a new session whose tokens carry the selected user and role
+func (server *Server) selectUser(ctx *gin.Context) {
+	var req selectUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserAccount(ctx, req.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if user.UserName != authPayload.Username {
+		err := errors.New("user dont belong to authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	session, valid := server.createSession(ctx, authPayload.Username, user.ID, user.UserRole)
+	if !valid {
+		return
+	}
+
+	rsp := responseSelectUser{
+		responseSession: session,
+		User:            user,
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,20 @@
+CREATE TABLE "revoked_tokens" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz DEFAULT (now()) NOT NULL
+);
+
+CREATE TABLE "account_revocations" (
+  "username" varchar PRIMARY KEY,
+  "revoked_before" timestamptz NOT NULL,
+  "updated_at" timestamptz DEFAULT (now()) NOT NULL
+);
+
+CREATE INDEX ON "revoked_tokens" ("expires_at");
+
+COMMENT ON COLUMN "account_revocations"."revoked_before" IS 'every token issued before this time is rejected';
+
+ALTER TABLE "revoked_tokens" ADD FOREIGN KEY ("username") REFERENCES "accounts" ("username");
+
+ALTER TABLE "account_revocations" ADD FOREIGN KEY ("username") REFERENCES "accounts" ("username");
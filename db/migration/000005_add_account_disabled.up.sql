@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD COLUMN "is_disabled" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "accounts"."is_disabled" IS 'disabled accounts are not able to login';
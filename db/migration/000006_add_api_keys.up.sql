@@ -0,0 +1,23 @@
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "prefix" varchar UNIQUE NOT NULL,
+  "hashed_key" varchar NOT NULL,
+  "user_id" bigint NOT NULL,
+  "role" varchar NOT NULL,
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL
+);
+
+CREATE INDEX ON "api_keys" ("username");
+
+COMMENT ON COLUMN "api_keys"."prefix" IS 'public part of the key used to look it up';
+
+COMMENT ON COLUMN "api_keys"."hashed_key" IS 'sha256 of the whole key';
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("username") REFERENCES "accounts" ("username");
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,12 @@
+CREATE TABLE "login_failures" (
+  "scope" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "failed_count" int NOT NULL DEFAULT 0,
+  "locked_until" timestamptz,
+  "last_failed_at" timestamptz DEFAULT (now()) NOT NULL,
+  PRIMARY KEY ("scope", "subject")
+);
+
+COMMENT ON COLUMN "login_failures"."scope" IS 'username or ip';
+
+COMMENT ON COLUMN "login_failures"."locked_until" IS 'login is refused until this time';
@@ -0,0 +1,5 @@
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "recovery_codes";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "totp_enabled";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "totp_secret";
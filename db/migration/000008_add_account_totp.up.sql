@@ -0,0 +1,11 @@
+ALTER TABLE "accounts" ADD COLUMN "totp_secret" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "accounts" ADD COLUMN "totp_enabled" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "accounts" ADD COLUMN "recovery_codes" varchar[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN "accounts"."totp_secret" IS 'base32 TOTP secret, empty until enrollment';
+
+COMMENT ON COLUMN "accounts"."totp_enabled" IS 'login requires a TOTP code once enabled';
+
+COMMENT ON COLUMN "accounts"."recovery_codes" IS 'sha256 of the unused recovery codes';
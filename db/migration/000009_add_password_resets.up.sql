@@ -0,0 +1,15 @@
+CREATE TABLE "password_resets" (
+  "hashed_token" varchar PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL
+);
+
+CREATE INDEX ON "password_resets" ("username");
+
+COMMENT ON COLUMN "password_resets"."hashed_token" IS 'sha256 of the reset token';
+
+COMMENT ON COLUMN "password_resets"."used_at" IS 'a reset token works a single time';
+
+ALTER TABLE "password_resets" ADD FOREIGN KEY ("username") REFERENCES "accounts" ("username");
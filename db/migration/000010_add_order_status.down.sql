@@ -0,0 +1,15 @@
+ALTER TABLE "poke_orders" DROP CONSTRAINT IF EXISTS "poke_orders_status_check";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "updated_at";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "refunded_at";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "cancelled_at";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "fulfilled_at";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "paid_at";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE "poke_orders" ADD COLUMN "order_detail" varchar NOT NULL DEFAULT 'selling';
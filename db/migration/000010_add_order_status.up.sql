@@ -0,0 +1,24 @@
+ALTER TABLE "poke_orders" DROP COLUMN "order_detail";
+
+-- the orders placed before the status existed are closed as fulfilled, a cancelled one was restocked
+-- already but never marked, so none of them may be cancelled or refunded into the stock again
+ALTER TABLE "poke_orders" ADD COLUMN "status" varchar NOT NULL DEFAULT 'fulfilled';
+
+ALTER TABLE "poke_orders" ALTER COLUMN "status" SET DEFAULT 'pending';
+
+ALTER TABLE "poke_orders" ADD COLUMN "paid_at" timestamptz;
+
+ALTER TABLE "poke_orders" ADD COLUMN "fulfilled_at" timestamptz;
+
+ALTER TABLE "poke_orders" ADD COLUMN "cancelled_at" timestamptz;
+
+ALTER TABLE "poke_orders" ADD COLUMN "refunded_at" timestamptz;
+
+ALTER TABLE "poke_orders" ADD COLUMN "updated_at" timestamptz DEFAULT (now()) NOT NULL;
+
+ALTER TABLE "poke_orders" ADD CONSTRAINT "poke_orders_status_check"
+  CHECK ("status" IN ('pending', 'paid', 'fulfilled', 'cancelled', 'refunded'));
+
+CREATE INDEX ON "poke_orders" ("status");
+
+COMMENT ON COLUMN "poke_orders"."status" IS 'pending, paid, fulfilled, cancelled or refunded';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "cart_items";
+
+DROP TABLE IF EXISTS "order_items";
+
+DELETE FROM "poke_orders" WHERE "product_id" IS NULL;
+
+ALTER TABLE "poke_orders" ALTER COLUMN "product_id" SET NOT NULL;
@@ -0,0 +1,50 @@
+CREATE TABLE "cart_items" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL,
+  "product_id" bigint NOT NULL,
+  "quantity" int NOT NULL,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL,
+  "updated_at" timestamptz DEFAULT (now()) NOT NULL,
+  CONSTRAINT "cart_items_quantity_check" CHECK ("quantity" > 0)
+);
+
+CREATE TABLE "order_items" (
+  "id" bigserial PRIMARY KEY,
+  "order_id" bigint NOT NULL,
+  "product_id" bigint NOT NULL,
+  "quantity" int NOT NULL,
+  "unit_price" bigint NOT NULL,
+  "total_price" bigint NOT NULL,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL,
+  CONSTRAINT "order_items_quantity_check" CHECK ("quantity" > 0)
+);
+
+CREATE UNIQUE INDEX ON "cart_items" ("user_id", "product_id");
+
+CREATE INDEX ON "order_items" ("order_id");
+
+CREATE INDEX ON "order_items" ("product_id");
+
+COMMENT ON COLUMN "cart_items"."quantity" IS 'must be positive';
+
+COMMENT ON COLUMN "order_items"."unit_price" IS 'price of the product when the order was placed';
+
+COMMENT ON COLUMN "order_items"."total_price" IS 'quantity times unit price';
+
+ALTER TABLE "cart_items" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "cart_items" ADD FOREIGN KEY ("product_id") REFERENCES "poke_products" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "order_items" ADD FOREIGN KEY ("order_id") REFERENCES "poke_orders" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "order_items" ADD FOREIGN KEY ("product_id") REFERENCES "poke_products" ("id");
+
+-- the unit price of an old order is rounded to the nearest minor unit, its total price stays what was paid.
+-- An old order without quantity has no line to move, the CHECK on order_items would reject it
+INSERT INTO "order_items" ("order_id", "product_id", "quantity", "unit_price", "total_price", "created_at")
+SELECT "id", "product_id", "quantity", ROUND("total_price"::numeric / NULLIF("quantity", 0))::bigint, "total_price", "created_at" FROM "poke_orders"
+WHERE "quantity" > 0;
+
+ALTER TABLE "poke_orders" ALTER COLUMN "product_id" DROP NOT NULL;
+
+COMMENT ON COLUMN "poke_orders"."product_id" IS 'only set on single product orders, the lines are on order_items';
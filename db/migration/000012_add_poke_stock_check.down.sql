@@ -0,0 +1 @@
+ALTER TABLE "poke_products" DROP CONSTRAINT IF EXISTS "poke_products_poke_stock_check";
@@ -0,0 +1,3 @@
+UPDATE "poke_products" SET "poke_stock" = 0 WHERE "poke_stock" < 0;
+
+ALTER TABLE "poke_products" ADD CONSTRAINT "poke_products_poke_stock_check" CHECK ("poke_stock" >= 0);
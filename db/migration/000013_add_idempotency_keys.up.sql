@@ -0,0 +1,17 @@
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "idempotency_key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_status" int NOT NULL DEFAULT 0,
+  "response_body" bytea NOT NULL DEFAULT '',
+  "created_at" timestamptz DEFAULT (now()) NOT NULL,
+  PRIMARY KEY ("username", "idempotency_key")
+);
+
+CREATE INDEX ON "idempotency_keys" ("created_at");
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of the method, path and body of the first request';
+
+COMMENT ON COLUMN "idempotency_keys"."response_status" IS '0 while the first request is running';
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "accounts" ("username") ON DELETE CASCADE;
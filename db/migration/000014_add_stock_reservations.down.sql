@@ -0,0 +1,9 @@
+UPDATE "poke_products" SET "poke_stock" = "poke_stock" + "reserved_stock";
+
+DROP TABLE IF EXISTS "stock_reservations";
+
+ALTER TABLE "poke_products" DROP CONSTRAINT IF EXISTS "poke_products_reserved_stock_check";
+
+ALTER TABLE "poke_products" DROP COLUMN IF EXISTS "on_hand_stock";
+
+ALTER TABLE "poke_products" DROP COLUMN IF EXISTS "reserved_stock";
@@ -0,0 +1,37 @@
+ALTER TABLE "poke_products" ADD COLUMN "reserved_stock" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "poke_products" ADD COLUMN "on_hand_stock" bigint GENERATED ALWAYS AS ("poke_stock" + "reserved_stock") STORED;
+
+ALTER TABLE "poke_products" ADD CONSTRAINT "poke_products_reserved_stock_check" CHECK ("reserved_stock" >= 0);
+
+COMMENT ON COLUMN "poke_products"."reserved_stock" IS 'held by active reservations, not available to orders';
+
+COMMENT ON COLUMN "poke_products"."on_hand_stock" IS 'available plus reserved stock';
+
+CREATE TABLE "stock_reservations" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL,
+  "product_id" bigint NOT NULL,
+  "quantity" int NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "order_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL,
+  "updated_at" timestamptz DEFAULT (now()) NOT NULL,
+  CONSTRAINT "stock_reservations_quantity_check" CHECK ("quantity" > 0),
+  CONSTRAINT "stock_reservations_status_check" CHECK ("status" IN ('active', 'converted', 'released'))
+);
+
+CREATE INDEX ON "stock_reservations" ("user_id");
+
+CREATE INDEX ON "stock_reservations" ("status", "expires_at");
+
+COMMENT ON COLUMN "stock_reservations"."status" IS 'active, converted or released';
+
+COMMENT ON COLUMN "stock_reservations"."order_id" IS 'the order the reservation was converted into';
+
+ALTER TABLE "stock_reservations" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "stock_reservations" ADD FOREIGN KEY ("product_id") REFERENCES "poke_products" ("id");
+
+ALTER TABLE "stock_reservations" ADD FOREIGN KEY ("order_id") REFERENCES "poke_orders" ("id");
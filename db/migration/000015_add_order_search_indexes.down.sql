@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "order_items_product_id_order_id_idx";
+
+DROP INDEX IF EXISTS "poke_orders_total_price_idx";
+
+DROP INDEX IF EXISTS "poke_orders_user_id_created_at_idx";
+
+DROP INDEX IF EXISTS "poke_orders_created_at_idx";
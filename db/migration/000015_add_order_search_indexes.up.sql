@@ -0,0 +1,7 @@
+CREATE INDEX "poke_orders_created_at_idx" ON "poke_orders" ("created_at");
+
+CREATE INDEX "poke_orders_user_id_created_at_idx" ON "poke_orders" ("user_id", "created_at");
+
+CREATE INDEX "poke_orders_total_price_idx" ON "poke_orders" ("total_price");
+
+CREATE INDEX "order_items_product_id_order_id_idx" ON "order_items" ("product_id", "order_id");
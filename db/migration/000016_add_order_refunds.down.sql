@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "order_refunds";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "net_total";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "refunded_total";
+
+ALTER TABLE "order_items" DROP CONSTRAINT IF EXISTS "order_items_refunded_quantity_check";
+
+ALTER TABLE "order_items" DROP COLUMN IF EXISTS "refunded_quantity";
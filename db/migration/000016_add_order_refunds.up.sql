@@ -0,0 +1,43 @@
+ALTER TABLE "order_items" ADD COLUMN "refunded_quantity" int NOT NULL DEFAULT 0;
+
+ALTER TABLE "order_items" ADD CONSTRAINT "order_items_refunded_quantity_check" CHECK ("refunded_quantity" BETWEEN 0 AND "quantity");
+
+ALTER TABLE "poke_orders" ADD COLUMN "refunded_total" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "poke_orders" ADD COLUMN "net_total" bigint GENERATED ALWAYS AS ("total_price" - "refunded_total") STORED;
+
+UPDATE "poke_orders" SET "refunded_total" = "total_price" WHERE "status" IN ('cancelled', 'refunded');
+
+COMMENT ON COLUMN "order_items"."refunded_quantity" IS 'returned by partial refunds';
+
+COMMENT ON COLUMN "poke_orders"."refunded_total" IS 'given back by refunds, the whole total once cancelled or refunded';
+
+COMMENT ON COLUMN "poke_orders"."net_total" IS 'total price minus the refunded total';
+
+CREATE TABLE "order_refunds" (
+  "id" bigserial PRIMARY KEY,
+  "order_id" bigint NOT NULL,
+  "order_item_id" bigint NOT NULL,
+  "product_id" bigint NOT NULL,
+  "quantity" int NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "order_status" varchar NOT NULL,
+  "refunded_by" varchar NOT NULL,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL,
+  CONSTRAINT "order_refunds_quantity_check" CHECK ("quantity" > 0)
+);
+
+CREATE INDEX ON "order_refunds" ("order_id");
+
+COMMENT ON COLUMN "order_refunds"."amount" IS 'quantity times the unit price of the order item';
+
+COMMENT ON COLUMN "order_refunds"."order_status" IS 'status of the order when it was refunded, a pending order was only partially cancelled';
+
+COMMENT ON COLUMN "order_refunds"."refunded_by" IS 'username of the account which made the refund';
+
+ALTER TABLE "order_refunds" ADD FOREIGN KEY ("order_id") REFERENCES "poke_orders" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "order_refunds" ADD FOREIGN KEY ("order_item_id") REFERENCES "order_items" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "order_refunds" ADD FOREIGN KEY ("product_id") REFERENCES "poke_products" ("id");
@@ -0,0 +1,11 @@
+ALTER TABLE "order_items" DROP COLUMN IF EXISTS "currency";
+
+ALTER TABLE "order_items" DROP COLUMN IF EXISTS "product_name";
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "currency";
+
+ALTER TABLE "poke_products" DROP CONSTRAINT IF EXISTS "poke_products_currency_check";
+
+ALTER TABLE "poke_products" DROP COLUMN IF EXISTS "currency";
+
+COMMENT ON COLUMN "poke_products"."poke_price" IS 'must be positive';
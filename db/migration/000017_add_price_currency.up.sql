@@ -0,0 +1,28 @@
+ALTER TABLE "poke_products" ADD COLUMN "currency" varchar(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE "poke_products" ADD CONSTRAINT "poke_products_currency_check" CHECK ("currency" ~ '^[A-Z]{3}$');
+
+COMMENT ON COLUMN "poke_products"."poke_price" IS 'must be positive, in minor units of the currency, cents for USD';
+
+COMMENT ON COLUMN "poke_products"."currency" IS 'ISO 4217 code of the price';
+
+ALTER TABLE "poke_orders" ADD COLUMN "currency" varchar(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE "poke_orders" ALTER COLUMN "currency" DROP DEFAULT;
+
+COMMENT ON COLUMN "poke_orders"."currency" IS 'ISO 4217 code of every price of the order';
+
+ALTER TABLE "order_items" ADD COLUMN "product_name" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "order_items" ADD COLUMN "currency" varchar(3) NOT NULL DEFAULT 'USD';
+
+UPDATE "order_items" SET "product_name" = "poke_products"."poke_name"
+FROM "poke_products" WHERE "order_items"."product_id" = "poke_products"."id";
+
+ALTER TABLE "order_items" ALTER COLUMN "product_name" DROP DEFAULT;
+
+ALTER TABLE "order_items" ALTER COLUMN "currency" DROP DEFAULT;
+
+COMMENT ON COLUMN "order_items"."product_name" IS 'name of the product when the order was placed';
+
+COMMENT ON COLUMN "order_items"."currency" IS 'currency of the unit price when the order was placed';
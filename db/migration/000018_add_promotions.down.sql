@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS "order_discounts";
+
+DROP TABLE IF EXISTS "promotion_redemptions";
+
+DROP TABLE IF EXISTS "promotions";
+
+COMMENT ON COLUMN "order_refunds"."amount" IS 'quantity times the unit price of the order item';
+
+ALTER TABLE "order_items" DROP CONSTRAINT IF EXISTS "order_items_discount_amount_check";
+
+ALTER TABLE "order_items" DROP COLUMN IF EXISTS "discount_amount";
+
+COMMENT ON COLUMN "order_items"."total_price" IS 'quantity times unit price';
+
+ALTER TABLE "poke_orders" DROP COLUMN IF EXISTS "discount_total";
+
+COMMENT ON COLUMN "poke_orders"."total_price" IS 'must be positive';
+
+ALTER TABLE "poke_products" DROP COLUMN IF EXISTS "poke_type";
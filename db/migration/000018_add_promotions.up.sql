@@ -0,0 +1,101 @@
+ALTER TABLE "poke_products" ADD COLUMN "poke_type" varchar NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "poke_products"."poke_type" IS 'type of the pokemon, promotions may be scoped to it';
+
+ALTER TABLE "poke_orders" ADD COLUMN "discount_total" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "poke_orders"."total_price" IS 'paid for the order, after its discounts';
+
+COMMENT ON COLUMN "poke_orders"."discount_total" IS 'taken off the order by its promotion';
+
+ALTER TABLE "order_items" ADD COLUMN "discount_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "order_items" ADD CONSTRAINT "order_items_discount_amount_check" CHECK ("discount_amount" >= 0);
+
+COMMENT ON COLUMN "order_items"."total_price" IS 'quantity times the unit price, minus the discount amount';
+
+COMMENT ON COLUMN "order_items"."discount_amount" IS 'taken off the line by the promotion of the order';
+
+COMMENT ON COLUMN "order_refunds"."amount" IS 'share of the total price of the order item for the quantity';
+
+CREATE TABLE "promotions" (
+  "id" bigserial PRIMARY KEY,
+  "code" varchar UNIQUE NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "discount_type" varchar NOT NULL,
+  "discount_value" bigint NOT NULL,
+  "currency" varchar(3),
+  "product_id" bigint,
+  "poke_type" varchar,
+  "user_role" varchar,
+  "starts_at" timestamptz NOT NULL,
+  "ends_at" timestamptz,
+  "max_redemptions" int,
+  "max_redemptions_per_user" int,
+  "redemption_count" int NOT NULL DEFAULT 0,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL,
+  CONSTRAINT "promotions_discount_type_check" CHECK ("discount_type" IN ('percentage', 'fixed_amount')),
+  CONSTRAINT "promotions_discount_value_check" CHECK ("discount_value" > 0 AND ("discount_type" <> 'percentage' OR "discount_value" <= 100)),
+  CONSTRAINT "promotions_currency_check" CHECK (("discount_type" = 'fixed_amount') = ("currency" IS NOT NULL)),
+  CONSTRAINT "promotions_window_check" CHECK ("ends_at" IS NULL OR "ends_at" > "starts_at"),
+  CONSTRAINT "promotions_max_redemptions_check" CHECK ("max_redemptions" > 0 AND "max_redemptions_per_user" > 0),
+  CONSTRAINT "promotions_redemption_count_check" CHECK ("redemption_count" BETWEEN 0 AND COALESCE("max_redemptions", "redemption_count"))
+);
+
+COMMENT ON COLUMN "promotions"."discount_type" IS 'percentage or fixed_amount';
+
+COMMENT ON COLUMN "promotions"."discount_value" IS 'percent off every line in scope, or minor units of the currency off the lines in scope';
+
+COMMENT ON COLUMN "promotions"."currency" IS 'ISO 4217 code of a fixed amount';
+
+COMMENT ON COLUMN "promotions"."product_id" IS 'only this product is discounted when set';
+
+COMMENT ON COLUMN "promotions"."poke_type" IS 'only pokemon of this type are discounted when set';
+
+COMMENT ON COLUMN "promotions"."user_role" IS 'only users of this role may redeem it when set';
+
+COMMENT ON COLUMN "promotions"."ends_at" IS 'the promotion never ends when null';
+
+COMMENT ON COLUMN "promotions"."max_redemptions" IS 'redemptions of every user together, unlimited when null';
+
+COMMENT ON COLUMN "promotions"."max_redemptions_per_user" IS 'redemptions of a single user, unlimited when null';
+
+COMMENT ON COLUMN "promotions"."created_by" IS 'username of the account which made the promotion';
+
+CREATE TABLE "promotion_redemptions" (
+  "id" bigserial PRIMARY KEY,
+  "promotion_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "order_id" bigint UNIQUE NOT NULL,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL
+);
+
+CREATE INDEX ON "promotion_redemptions" ("promotion_id", "user_id");
+
+ALTER TABLE "promotion_redemptions" ADD FOREIGN KEY ("promotion_id") REFERENCES "promotions" ("id");
+
+ALTER TABLE "promotion_redemptions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "promotion_redemptions" ADD FOREIGN KEY ("order_id") REFERENCES "poke_orders" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "order_discounts" (
+  "id" bigserial PRIMARY KEY,
+  "order_id" bigint NOT NULL,
+  "order_item_id" bigint NOT NULL,
+  "promotion_id" bigint NOT NULL,
+  "code" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz DEFAULT (now()) NOT NULL,
+  CONSTRAINT "order_discounts_amount_check" CHECK ("amount" > 0)
+);
+
+CREATE INDEX ON "order_discounts" ("order_id");
+
+COMMENT ON COLUMN "order_discounts"."code" IS 'code of the promotion when the order was placed';
+
+ALTER TABLE "order_discounts" ADD FOREIGN KEY ("order_id") REFERENCES "poke_orders" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "order_discounts" ADD FOREIGN KEY ("order_item_id") REFERENCES "order_items" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "order_discounts" ADD FOREIGN KEY ("promotion_id") REFERENCES "promotions" ("id");
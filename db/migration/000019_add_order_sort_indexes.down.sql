@@ -0,0 +1,13 @@
+CREATE INDEX IF NOT EXISTS "poke_orders_total_price_idx" ON "poke_orders" ("total_price");
+
+CREATE INDEX IF NOT EXISTS "poke_orders_user_id_created_at_idx" ON "poke_orders" ("user_id", "created_at");
+
+CREATE INDEX IF NOT EXISTS "poke_orders_created_at_idx" ON "poke_orders" ("created_at");
+
+DROP INDEX IF EXISTS "poke_orders_quantity_id_idx";
+
+DROP INDEX IF EXISTS "poke_orders_total_price_id_idx";
+
+DROP INDEX IF EXISTS "poke_orders_user_id_created_at_id_idx";
+
+DROP INDEX IF EXISTS "poke_orders_created_at_id_idx";
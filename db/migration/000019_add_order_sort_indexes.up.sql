@@ -0,0 +1,14 @@
+-- the order listings sort by one of these columns then by id, an index on both lets them be read in order
+CREATE INDEX "poke_orders_created_at_id_idx" ON "poke_orders" ("created_at", "id");
+
+CREATE INDEX "poke_orders_user_id_created_at_id_idx" ON "poke_orders" ("user_id", "created_at", "id");
+
+CREATE INDEX "poke_orders_total_price_id_idx" ON "poke_orders" ("total_price", "id");
+
+CREATE INDEX "poke_orders_quantity_id_idx" ON "poke_orders" ("quantity", "id");
+
+DROP INDEX IF EXISTS "poke_orders_created_at_idx";
+
+DROP INDEX IF EXISTS "poke_orders_user_id_created_at_idx";
+
+DROP INDEX IF EXISTS "poke_orders_total_price_idx";
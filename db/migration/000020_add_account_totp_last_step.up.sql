@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD COLUMN "totp_last_step" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "accounts"."totp_last_step" IS 'time step of the last accepted TOTP code, a code of the same or an earlier step is a replay';
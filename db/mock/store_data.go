@@ -7,8 +7,10 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	db "github.com/gunhachi/poke-blackmarket/db/sqlc"
 )
 
@@ -35,6 +37,36 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AddCartItem mocks base method.
+func (m *MockStore) AddCartItem(arg0 context.Context, arg1 db.AddCartItemParams) (db.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCartItem", arg0, arg1)
+	ret0, _ := ret[0].(db.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCartItem indicates an expected call of AddCartItem.
+func (mr *MockStoreMockRecorder) AddCartItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCartItem", reflect.TypeOf((*MockStore)(nil).AddCartItem), arg0, arg1)
+}
+
+// AddPokemonOrderRefundedTotal mocks base method.
+func (m *MockStore) AddPokemonOrderRefundedTotal(arg0 context.Context, arg1 db.AddPokemonOrderRefundedTotalParams) (db.PokeOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPokemonOrderRefundedTotal", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPokemonOrderRefundedTotal indicates an expected call of AddPokemonOrderRefundedTotal.
+func (mr *MockStoreMockRecorder) AddPokemonOrderRefundedTotal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPokemonOrderRefundedTotal", reflect.TypeOf((*MockStore)(nil).AddPokemonOrderRefundedTotal), arg0, arg1)
+}
+
 // AddPokemonStockData mocks base method.
 func (m *MockStore) AddPokemonStockData(arg0 context.Context, arg1 db.AddPokemonStockDataParams) (db.PokeProduct, error) {
 	m.ctrl.T.Helper()
@@ -50,11 +82,40 @@ func (mr *MockStoreMockRecorder) AddPokemonStockData(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPokemonStockData", reflect.TypeOf((*MockStore)(nil).AddPokemonStockData), arg0, arg1)
 }
 
+// BlockAccountSessions mocks base method.
+func (m *MockStore) BlockAccountSessions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockAccountSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockAccountSessions indicates an expected call of BlockAccountSessions.
+func (mr *MockStoreMockRecorder) BlockAccountSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockAccountSessions", reflect.TypeOf((*MockStore)(nil).BlockAccountSessions), arg0, arg1)
+}
+
+// BlockSession mocks base method.
+func (m *MockStore) BlockSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockSession indicates an expected call of BlockSession.
+func (mr *MockStoreMockRecorder) BlockSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSession", reflect.TypeOf((*MockStore)(nil).BlockSession), arg0, arg1)
+}
+
 // CancelOrderTx mocks base method.
-func (m *MockStore) CancelOrderTx(arg0 context.Context, arg1 db.CancelOrderParam) (string, error) {
+func (m *MockStore) CancelOrderTx(arg0 context.Context, arg1 db.CancelOrderParam) (db.OrderTxResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CancelOrderTx", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(db.OrderTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -79,6 +140,95 @@ func (mr *MockStoreMockRecorder) CancelPokemonOrderData(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPokemonOrderData", reflect.TypeOf((*MockStore)(nil).CancelPokemonOrderData), arg0, arg1)
 }
 
+// ChangePasswordTx mocks base method.
+func (m *MockStore) ChangePasswordTx(arg0 context.Context, arg1 db.ChangePasswordTxParams) (db.ChangePasswordTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ChangePasswordTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePasswordTx indicates an expected call of ChangePasswordTx.
+func (mr *MockStoreMockRecorder) ChangePasswordTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePasswordTx", reflect.TypeOf((*MockStore)(nil).ChangePasswordTx), arg0, arg1)
+}
+
+// CheckoutTx mocks base method.
+func (m *MockStore) CheckoutTx(arg0 context.Context, arg1 db.CheckoutTxParams) (db.CheckoutTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckoutTx", arg0, arg1)
+	ret0, _ := ret[0].(db.CheckoutTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckoutTx indicates an expected call of CheckoutTx.
+func (mr *MockStoreMockRecorder) CheckoutTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckoutTx", reflect.TypeOf((*MockStore)(nil).CheckoutTx), arg0, arg1)
+}
+
+// ClaimReservedStockData mocks base method.
+func (m *MockStore) ClaimReservedStockData(arg0 context.Context, arg1 db.ClaimReservedStockDataParams) (db.PokeProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimReservedStockData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimReservedStockData indicates an expected call of ClaimReservedStockData.
+func (mr *MockStoreMockRecorder) ClaimReservedStockData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimReservedStockData", reflect.TypeOf((*MockStore)(nil).ClaimReservedStockData), arg0, arg1)
+}
+
+// ClearCart mocks base method.
+func (m *MockStore) ClearCart(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCart", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCart indicates an expected call of ClearCart.
+func (mr *MockStoreMockRecorder) ClearCart(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCart", reflect.TypeOf((*MockStore)(nil).ClearCart), arg0, arg1)
+}
+
+// ConvertReservationTx mocks base method.
+func (m *MockStore) ConvertReservationTx(arg0 context.Context, arg1 db.ReservationTxParams) (db.ConvertReservationTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertReservationTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ConvertReservationTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConvertReservationTx indicates an expected call of ConvertReservationTx.
+func (mr *MockStoreMockRecorder) ConvertReservationTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertReservationTx", reflect.TypeOf((*MockStore)(nil).ConvertReservationTx), arg0, arg1)
+}
+
+// CountUserPromotionRedemptions mocks base method.
+func (m *MockStore) CountUserPromotionRedemptions(arg0 context.Context, arg1 db.CountUserPromotionRedemptionsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserPromotionRedemptions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserPromotionRedemptions indicates an expected call of CountUserPromotionRedemptions.
+func (mr *MockStoreMockRecorder) CountUserPromotionRedemptions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserPromotionRedemptions", reflect.TypeOf((*MockStore)(nil).CountUserPromotionRedemptions), arg0, arg1)
+}
+
 // CreateAccountLog mocks base method.
 func (m *MockStore) CreateAccountLog(arg0 context.Context, arg1 db.CreateAccountLogParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -94,241 +244,1162 @@ func (mr *MockStoreMockRecorder) CreateAccountLog(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountLog", reflect.TypeOf((*MockStore)(nil).CreateAccountLog), arg0, arg1)
 }
 
-// CreatePokemonData mocks base method.
-func (m *MockStore) CreatePokemonData(arg0 context.Context, arg1 db.CreatePokemonDataParams) (db.PokeProduct, error) {
+// CreateApiKey mocks base method.
+func (m *MockStore) CreateApiKey(arg0 context.Context, arg1 db.CreateApiKeyParams) (db.ApiKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePokemonData", arg0, arg1)
-	ret0, _ := ret[0].(db.PokeProduct)
+	ret := m.ctrl.Call(m, "CreateApiKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreatePokemonData indicates an expected call of CreatePokemonData.
-func (mr *MockStoreMockRecorder) CreatePokemonData(arg0, arg1 interface{}) *gomock.Call {
+// CreateApiKey indicates an expected call of CreateApiKey.
+func (mr *MockStoreMockRecorder) CreateApiKey(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePokemonData", reflect.TypeOf((*MockStore)(nil).CreatePokemonData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApiKey", reflect.TypeOf((*MockStore)(nil).CreateApiKey), arg0, arg1)
 }
 
-// CreateUserAccount mocks base method.
-func (m *MockStore) CreateUserAccount(arg0 context.Context, arg1 db.CreateUserAccountParams) (db.User, error) {
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(arg0 context.Context, arg1 db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUserAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUserAccount indicates an expected call of CreateUserAccount.
-func (mr *MockStoreMockRecorder) CreateUserAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserAccount", reflect.TypeOf((*MockStore)(nil).CreateUserAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), arg0, arg1)
 }
 
-// DeductPokemonStockData mocks base method.
-func (m *MockStore) DeductPokemonStockData(arg0 context.Context, arg1 db.DeductPokemonStockDataParams) (db.PokeProduct, error) {
+// CreateOrderDiscount mocks base method.
+func (m *MockStore) CreateOrderDiscount(arg0 context.Context, arg1 db.CreateOrderDiscountParams) (db.OrderDiscount, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeductPokemonStockData", arg0, arg1)
-	ret0, _ := ret[0].(db.PokeProduct)
+	ret := m.ctrl.Call(m, "CreateOrderDiscount", arg0, arg1)
+	ret0, _ := ret[0].(db.OrderDiscount)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DeductPokemonStockData indicates an expected call of DeductPokemonStockData.
-func (mr *MockStoreMockRecorder) DeductPokemonStockData(arg0, arg1 interface{}) *gomock.Call {
+// CreateOrderDiscount indicates an expected call of CreateOrderDiscount.
+func (mr *MockStoreMockRecorder) CreateOrderDiscount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductPokemonStockData", reflect.TypeOf((*MockStore)(nil).DeductPokemonStockData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderDiscount", reflect.TypeOf((*MockStore)(nil).CreateOrderDiscount), arg0, arg1)
 }
 
-// DeleteUserAccount mocks base method.
-func (m *MockStore) DeleteUserAccount(arg0 context.Context, arg1 int64) error {
+// CreateOrderItem mocks base method.
+func (m *MockStore) CreateOrderItem(arg0 context.Context, arg1 db.CreateOrderItemParams) (db.OrderItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUserAccount", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CreateOrderItem", arg0, arg1)
+	ret0, _ := ret[0].(db.OrderItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteUserAccount indicates an expected call of DeleteUserAccount.
-func (mr *MockStoreMockRecorder) DeleteUserAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreateOrderItem indicates an expected call of CreateOrderItem.
+func (mr *MockStoreMockRecorder) CreateOrderItem(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserAccount", reflect.TypeOf((*MockStore)(nil).DeleteUserAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderItem", reflect.TypeOf((*MockStore)(nil).CreateOrderItem), arg0, arg1)
 }
 
-// GetAccountLog mocks base method.
-func (m *MockStore) GetAccountLog(arg0 context.Context, arg1 string) (db.Account, error) {
+// CreateOrderRefund mocks base method.
+func (m *MockStore) CreateOrderRefund(arg0 context.Context, arg1 db.CreateOrderRefundParams) (db.OrderRefund, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountLog", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "CreateOrderRefund", arg0, arg1)
+	ret0, _ := ret[0].(db.OrderRefund)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountLog indicates an expected call of GetAccountLog.
-func (mr *MockStoreMockRecorder) GetAccountLog(arg0, arg1 interface{}) *gomock.Call {
+// CreateOrderRefund indicates an expected call of CreateOrderRefund.
+func (mr *MockStoreMockRecorder) CreateOrderRefund(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountLog", reflect.TypeOf((*MockStore)(nil).GetAccountLog), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderRefund", reflect.TypeOf((*MockStore)(nil).CreateOrderRefund), arg0, arg1)
 }
 
-// GetPokemonData mocks base method.
-func (m *MockStore) GetPokemonData(arg0 context.Context, arg1 int64) (db.PokeProduct, error) {
+// CreatePasswordReset mocks base method.
+func (m *MockStore) CreatePasswordReset(arg0 context.Context, arg1 db.CreatePasswordResetParams) (db.PasswordReset, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPokemonData", arg0, arg1)
-	ret0, _ := ret[0].(db.PokeProduct)
+	ret := m.ctrl.Call(m, "CreatePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPokemonData indicates an expected call of GetPokemonData.
-func (mr *MockStoreMockRecorder) GetPokemonData(arg0, arg1 interface{}) *gomock.Call {
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockStoreMockRecorder) CreatePasswordReset(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonData", reflect.TypeOf((*MockStore)(nil).GetPokemonData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockStore)(nil).CreatePasswordReset), arg0, arg1)
 }
 
-// GetPokemonOrderData mocks base method.
-func (m *MockStore) GetPokemonOrderData(arg0 context.Context, arg1 int64) (db.PokeOrder, error) {
+// CreatePokemonData mocks base method.
+func (m *MockStore) CreatePokemonData(arg0 context.Context, arg1 db.CreatePokemonDataParams) (db.PokeProduct, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPokemonOrderData", arg0, arg1)
-	ret0, _ := ret[0].(db.PokeOrder)
+	ret := m.ctrl.Call(m, "CreatePokemonData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeProduct)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPokemonOrderData indicates an expected call of GetPokemonOrderData.
-func (mr *MockStoreMockRecorder) GetPokemonOrderData(arg0, arg1 interface{}) *gomock.Call {
+// CreatePokemonData indicates an expected call of CreatePokemonData.
+func (mr *MockStoreMockRecorder) CreatePokemonData(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonOrderData", reflect.TypeOf((*MockStore)(nil).GetPokemonOrderData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePokemonData", reflect.TypeOf((*MockStore)(nil).CreatePokemonData), arg0, arg1)
 }
 
-// GetUserAccount mocks base method.
-func (m *MockStore) GetUserAccount(arg0 context.Context, arg1 int64) (db.User, error) {
+// CreatePromotion mocks base method.
+func (m *MockStore) CreatePromotion(arg0 context.Context, arg1 db.CreatePromotionParams) (db.Promotion, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "CreatePromotion", arg0, arg1)
+	ret0, _ := ret[0].(db.Promotion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserAccount indicates an expected call of GetUserAccount.
-func (mr *MockStoreMockRecorder) GetUserAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreatePromotion indicates an expected call of CreatePromotion.
+func (mr *MockStoreMockRecorder) CreatePromotion(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccount", reflect.TypeOf((*MockStore)(nil).GetUserAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromotion", reflect.TypeOf((*MockStore)(nil).CreatePromotion), arg0, arg1)
 }
 
-// InsertPokemonOrderData mocks base method.
-func (m *MockStore) InsertPokemonOrderData(arg0 context.Context, arg1 db.InsertPokemonOrderDataParams) (db.PokeOrder, error) {
+// CreatePromotionRedemption mocks base method.
+func (m *MockStore) CreatePromotionRedemption(arg0 context.Context, arg1 db.CreatePromotionRedemptionParams) (db.PromotionRedemption, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertPokemonOrderData", arg0, arg1)
-	ret0, _ := ret[0].(db.PokeOrder)
+	ret := m.ctrl.Call(m, "CreatePromotionRedemption", arg0, arg1)
+	ret0, _ := ret[0].(db.PromotionRedemption)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertPokemonOrderData indicates an expected call of InsertPokemonOrderData.
-func (mr *MockStoreMockRecorder) InsertPokemonOrderData(arg0, arg1 interface{}) *gomock.Call {
+// CreatePromotionRedemption indicates an expected call of CreatePromotionRedemption.
+func (mr *MockStoreMockRecorder) CreatePromotionRedemption(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPokemonOrderData", reflect.TypeOf((*MockStore)(nil).InsertPokemonOrderData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromotionRedemption", reflect.TypeOf((*MockStore)(nil).CreatePromotionRedemption), arg0, arg1)
 }
 
-// ListOrderDetailedData mocks base method.
-func (m *MockStore) ListOrderDetailedData(arg0 context.Context, arg1 db.ListOrderDetailedDataParams) ([]db.ListOrderDetailedDataRow, error) {
+// CreateRevokedToken mocks base method.
+func (m *MockStore) CreateRevokedToken(arg0 context.Context, arg1 db.CreateRevokedTokenParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListOrderDetailedData", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListOrderDetailedDataRow)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateRevokedToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ListOrderDetailedData indicates an expected call of ListOrderDetailedData.
-func (mr *MockStoreMockRecorder) ListOrderDetailedData(arg0, arg1 interface{}) *gomock.Call {
+// CreateRevokedToken indicates an expected call of CreateRevokedToken.
+func (mr *MockStoreMockRecorder) CreateRevokedToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrderDetailedData", reflect.TypeOf((*MockStore)(nil).ListOrderDetailedData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRevokedToken", reflect.TypeOf((*MockStore)(nil).CreateRevokedToken), arg0, arg1)
 }
 
-// ListPokemonData mocks base method.
-func (m *MockStore) ListPokemonData(arg0 context.Context, arg1 db.ListPokemonDataParams) ([]db.PokeProduct, error) {
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListPokemonData", arg0, arg1)
-	ret0, _ := ret[0].([]db.PokeProduct)
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListPokemonData indicates an expected call of ListPokemonData.
-func (mr *MockStoreMockRecorder) ListPokemonData(arg0, arg1 interface{}) *gomock.Call {
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPokemonData", reflect.TypeOf((*MockStore)(nil).ListPokemonData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
 }
 
-// ListPokemonOrderData mocks base method.
-func (m *MockStore) ListPokemonOrderData(arg0 context.Context, arg1 db.ListPokemonOrderDataParams) ([]db.PokeOrder, error) {
+// CreateStockReservation mocks base method.
+func (m *MockStore) CreateStockReservation(arg0 context.Context, arg1 db.CreateStockReservationParams) (db.StockReservation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListPokemonOrderData", arg0, arg1)
-	ret0, _ := ret[0].([]db.PokeOrder)
+	ret := m.ctrl.Call(m, "CreateStockReservation", arg0, arg1)
+	ret0, _ := ret[0].(db.StockReservation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListPokemonOrderData indicates an expected call of ListPokemonOrderData.
-func (mr *MockStoreMockRecorder) ListPokemonOrderData(arg0, arg1 interface{}) *gomock.Call {
+// CreateStockReservation indicates an expected call of CreateStockReservation.
+func (mr *MockStoreMockRecorder) CreateStockReservation(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPokemonOrderData", reflect.TypeOf((*MockStore)(nil).ListPokemonOrderData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStockReservation", reflect.TypeOf((*MockStore)(nil).CreateStockReservation), arg0, arg1)
 }
 
-// ListUserAccount mocks base method.
-func (m *MockStore) ListUserAccount(arg0 context.Context, arg1 db.ListUserAccountParams) ([]db.User, error) {
+// CreateUserAccount mocks base method.
+func (m *MockStore) CreateUserAccount(arg0 context.Context, arg1 db.CreateUserAccountParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUserAccount", arg0, arg1)
-	ret0, _ := ret[0].([]db.User)
+	ret := m.ctrl.Call(m, "CreateUserAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListUserAccount indicates an expected call of ListUserAccount.
-func (mr *MockStoreMockRecorder) ListUserAccount(arg0, arg1 interface{}) *gomock.Call {
+// CreateUserAccount indicates an expected call of CreateUserAccount.
+func (mr *MockStoreMockRecorder) CreateUserAccount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserAccount", reflect.TypeOf((*MockStore)(nil).ListUserAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserAccount", reflect.TypeOf((*MockStore)(nil).CreateUserAccount), arg0, arg1)
 }
 
-// OrderTx mocks base method.
-func (m *MockStore) OrderTx(arg0 context.Context, arg1 db.OrderTxParams) (db.OrderTxResult, error) {
+// DeductPokemonStockData mocks base method.
+func (m *MockStore) DeductPokemonStockData(arg0 context.Context, arg1 db.DeductPokemonStockDataParams) (db.PokeProduct, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OrderTx", arg0, arg1)
-	ret0, _ := ret[0].(db.OrderTxResult)
+	ret := m.ctrl.Call(m, "DeductPokemonStockData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeProduct)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// OrderTx indicates an expected call of OrderTx.
-func (mr *MockStoreMockRecorder) OrderTx(arg0, arg1 interface{}) *gomock.Call {
+// DeductPokemonStockData indicates an expected call of DeductPokemonStockData.
+func (mr *MockStoreMockRecorder) DeductPokemonStockData(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderTx", reflect.TypeOf((*MockStore)(nil).OrderTx), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductPokemonStockData", reflect.TypeOf((*MockStore)(nil).DeductPokemonStockData), arg0, arg1)
 }
 
-// UpdatePokemonData mocks base method.
-func (m *MockStore) UpdatePokemonData(arg0 context.Context, arg1 db.UpdatePokemonDataParams) (db.PokeProduct, error) {
+// DeleteCartItem mocks base method.
+func (m *MockStore) DeleteCartItem(arg0 context.Context, arg1 db.DeleteCartItemParams) (db.CartItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePokemonData", arg0, arg1)
-	ret0, _ := ret[0].(db.PokeProduct)
+	ret := m.ctrl.Call(m, "DeleteCartItem", arg0, arg1)
+	ret0, _ := ret[0].(db.CartItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdatePokemonData indicates an expected call of UpdatePokemonData.
-func (mr *MockStoreMockRecorder) UpdatePokemonData(arg0, arg1 interface{}) *gomock.Call {
+// DeleteCartItem indicates an expected call of DeleteCartItem.
+func (mr *MockStoreMockRecorder) DeleteCartItem(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePokemonData", reflect.TypeOf((*MockStore)(nil).UpdatePokemonData), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCartItem", reflect.TypeOf((*MockStore)(nil).DeleteCartItem), arg0, arg1)
 }
 
-// UpdateUserAccountRole mocks base method.
-func (m *MockStore) UpdateUserAccountRole(arg0 context.Context, arg1 db.UpdateUserAccountRoleParams) (db.User, error) {
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockStore) DeleteExpiredIdempotencyKeys(arg0 context.Context, arg1 time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserAccountRole", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// UpdateUserAccountRole indicates an expected call of UpdateUserAccountRole.
-func (mr *MockStoreMockRecorder) UpdateUserAccountRole(arg0, arg1 interface{}) *gomock.Call {
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockStoreMockRecorder) DeleteExpiredIdempotencyKeys(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserAccountRole", reflect.TypeOf((*MockStore)(nil).UpdateUserAccountRole), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).DeleteExpiredIdempotencyKeys), arg0, arg1)
+}
+
+// DeleteExpiredRevokedTokens mocks base method.
+func (m *MockStore) DeleteExpiredRevokedTokens(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRevokedTokens", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredRevokedTokens indicates an expected call of DeleteExpiredRevokedTokens.
+func (mr *MockStoreMockRecorder) DeleteExpiredRevokedTokens(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRevokedTokens", reflect.TypeOf((*MockStore)(nil).DeleteExpiredRevokedTokens), arg0)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(arg0 context.Context, arg1 db.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), arg0, arg1)
+}
+
+// DeleteLoginFailure mocks base method.
+func (m *MockStore) DeleteLoginFailure(arg0 context.Context, arg1 db.DeleteLoginFailureParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginFailure indicates an expected call of DeleteLoginFailure.
+func (mr *MockStoreMockRecorder) DeleteLoginFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginFailure", reflect.TypeOf((*MockStore)(nil).DeleteLoginFailure), arg0, arg1)
+}
+
+// DeleteUserAccount mocks base method.
+func (m *MockStore) DeleteUserAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserAccount indicates an expected call of DeleteUserAccount.
+func (mr *MockStoreMockRecorder) DeleteUserAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserAccount", reflect.TypeOf((*MockStore)(nil).DeleteUserAccount), arg0, arg1)
+}
+
+// ExpirePasswordResets mocks base method.
+func (m *MockStore) ExpirePasswordResets(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePasswordResets", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpirePasswordResets indicates an expected call of ExpirePasswordResets.
+func (mr *MockStoreMockRecorder) ExpirePasswordResets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePasswordResets", reflect.TypeOf((*MockStore)(nil).ExpirePasswordResets), arg0, arg1)
+}
+
+// GetAccountLog mocks base method.
+func (m *MockStore) GetAccountLog(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountLog", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountLog indicates an expected call of GetAccountLog.
+func (mr *MockStoreMockRecorder) GetAccountLog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountLog", reflect.TypeOf((*MockStore)(nil).GetAccountLog), arg0, arg1)
+}
+
+// GetAccountRevocation mocks base method.
+func (m *MockStore) GetAccountRevocation(arg0 context.Context, arg1 string) (db.AccountRevocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountRevocation", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountRevocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountRevocation indicates an expected call of GetAccountRevocation.
+func (mr *MockStoreMockRecorder) GetAccountRevocation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountRevocation", reflect.TypeOf((*MockStore)(nil).GetAccountRevocation), arg0, arg1)
+}
+
+// GetApiKeyByPrefix mocks base method.
+func (m *MockStore) GetApiKeyByPrefix(arg0 context.Context, arg1 string) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiKeyByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiKeyByPrefix indicates an expected call of GetApiKeyByPrefix.
+func (mr *MockStoreMockRecorder) GetApiKeyByPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKeyByPrefix", reflect.TypeOf((*MockStore)(nil).GetApiKeyByPrefix), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetLoginLockedUntil mocks base method.
+func (m *MockStore) GetLoginLockedUntil(arg0 context.Context, arg1 db.GetLoginLockedUntilParams) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginLockedUntil", arg0, arg1)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginLockedUntil indicates an expected call of GetLoginLockedUntil.
+func (mr *MockStoreMockRecorder) GetLoginLockedUntil(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginLockedUntil", reflect.TypeOf((*MockStore)(nil).GetLoginLockedUntil), arg0, arg1)
+}
+
+// GetPokemonData mocks base method.
+func (m *MockStore) GetPokemonData(arg0 context.Context, arg1 int64) (db.PokeProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPokemonData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPokemonData indicates an expected call of GetPokemonData.
+func (mr *MockStoreMockRecorder) GetPokemonData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonData", reflect.TypeOf((*MockStore)(nil).GetPokemonData), arg0, arg1)
+}
+
+// GetPokemonDataForUpdate mocks base method.
+func (m *MockStore) GetPokemonDataForUpdate(arg0 context.Context, arg1 int64) (db.PokeProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPokemonDataForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPokemonDataForUpdate indicates an expected call of GetPokemonDataForUpdate.
+func (mr *MockStoreMockRecorder) GetPokemonDataForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonDataForUpdate", reflect.TypeOf((*MockStore)(nil).GetPokemonDataForUpdate), arg0, arg1)
+}
+
+// GetPokemonOrderData mocks base method.
+func (m *MockStore) GetPokemonOrderData(arg0 context.Context, arg1 int64) (db.PokeOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPokemonOrderData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPokemonOrderData indicates an expected call of GetPokemonOrderData.
+func (mr *MockStoreMockRecorder) GetPokemonOrderData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonOrderData", reflect.TypeOf((*MockStore)(nil).GetPokemonOrderData), arg0, arg1)
+}
+
+// GetPokemonOrderDataForUpdate mocks base method.
+func (m *MockStore) GetPokemonOrderDataForUpdate(arg0 context.Context, arg1 int64) (db.PokeOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPokemonOrderDataForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPokemonOrderDataForUpdate indicates an expected call of GetPokemonOrderDataForUpdate.
+func (mr *MockStoreMockRecorder) GetPokemonOrderDataForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPokemonOrderDataForUpdate", reflect.TypeOf((*MockStore)(nil).GetPokemonOrderDataForUpdate), arg0, arg1)
+}
+
+// GetPromotion mocks base method.
+func (m *MockStore) GetPromotion(arg0 context.Context, arg1 int64) (db.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromotion", arg0, arg1)
+	ret0, _ := ret[0].(db.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromotion indicates an expected call of GetPromotion.
+func (mr *MockStoreMockRecorder) GetPromotion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotion", reflect.TypeOf((*MockStore)(nil).GetPromotion), arg0, arg1)
+}
+
+// GetPromotionByCodeForUpdate mocks base method.
+func (m *MockStore) GetPromotionByCodeForUpdate(arg0 context.Context, arg1 string) (db.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromotionByCodeForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromotionByCodeForUpdate indicates an expected call of GetPromotionByCodeForUpdate.
+func (mr *MockStoreMockRecorder) GetPromotionByCodeForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotionByCodeForUpdate", reflect.TypeOf((*MockStore)(nil).GetPromotionByCodeForUpdate), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetStockReservation mocks base method.
+func (m *MockStore) GetStockReservation(arg0 context.Context, arg1 int64) (db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockReservation", arg0, arg1)
+	ret0, _ := ret[0].(db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockReservation indicates an expected call of GetStockReservation.
+func (mr *MockStoreMockRecorder) GetStockReservation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockReservation", reflect.TypeOf((*MockStore)(nil).GetStockReservation), arg0, arg1)
+}
+
+// GetStockReservationForUpdate mocks base method.
+func (m *MockStore) GetStockReservationForUpdate(arg0 context.Context, arg1 int64) (db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockReservationForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockReservationForUpdate indicates an expected call of GetStockReservationForUpdate.
+func (mr *MockStoreMockRecorder) GetStockReservationForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockReservationForUpdate", reflect.TypeOf((*MockStore)(nil).GetStockReservationForUpdate), arg0, arg1)
+}
+
+// GetUserAccount mocks base method.
+func (m *MockStore) GetUserAccount(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAccount indicates an expected call of GetUserAccount.
+func (mr *MockStoreMockRecorder) GetUserAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccount", reflect.TypeOf((*MockStore)(nil).GetUserAccount), arg0, arg1)
+}
+
+// InsertPokemonOrderData mocks base method.
+func (m *MockStore) InsertPokemonOrderData(arg0 context.Context, arg1 db.InsertPokemonOrderDataParams) (db.PokeOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPokemonOrderData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPokemonOrderData indicates an expected call of InsertPokemonOrderData.
+func (mr *MockStoreMockRecorder) InsertPokemonOrderData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPokemonOrderData", reflect.TypeOf((*MockStore)(nil).InsertPokemonOrderData), arg0, arg1)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockStore) IsTokenRevoked(arg0 context.Context, arg1 uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockStoreMockRecorder) IsTokenRevoked(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockStore)(nil).IsTokenRevoked), arg0, arg1)
+}
+
+// ListApiKeys mocks base method.
+func (m *MockStore) ListApiKeys(arg0 context.Context, arg1 db.ListApiKeysParams) ([]db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApiKeys", arg0, arg1)
+	ret0, _ := ret[0].([]db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApiKeys indicates an expected call of ListApiKeys.
+func (mr *MockStoreMockRecorder) ListApiKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApiKeys", reflect.TypeOf((*MockStore)(nil).ListApiKeys), arg0, arg1)
+}
+
+// ListCartItems mocks base method.
+func (m *MockStore) ListCartItems(arg0 context.Context, arg1 int64) ([]db.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCartItems", arg0, arg1)
+	ret0, _ := ret[0].([]db.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCartItems indicates an expected call of ListCartItems.
+func (mr *MockStoreMockRecorder) ListCartItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCartItems", reflect.TypeOf((*MockStore)(nil).ListCartItems), arg0, arg1)
+}
+
+// ListExpiredStockReservations mocks base method.
+func (m *MockStore) ListExpiredStockReservations(arg0 context.Context, arg1 db.ListExpiredStockReservationsParams) ([]db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredStockReservations", arg0, arg1)
+	ret0, _ := ret[0].([]db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredStockReservations indicates an expected call of ListExpiredStockReservations.
+func (mr *MockStoreMockRecorder) ListExpiredStockReservations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredStockReservations", reflect.TypeOf((*MockStore)(nil).ListExpiredStockReservations), arg0, arg1)
+}
+
+// ListOrderDetailedData mocks base method.
+func (m *MockStore) ListOrderDetailedData(arg0 context.Context, arg1 db.ListOrderDetailedDataParams) ([]db.ListOrderDetailedDataRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrderDetailedData", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListOrderDetailedDataRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrderDetailedData indicates an expected call of ListOrderDetailedData.
+func (mr *MockStoreMockRecorder) ListOrderDetailedData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrderDetailedData", reflect.TypeOf((*MockStore)(nil).ListOrderDetailedData), arg0, arg1)
+}
+
+// ListOrderDiscounts mocks base method.
+func (m *MockStore) ListOrderDiscounts(arg0 context.Context, arg1 int64) ([]db.OrderDiscount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrderDiscounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.OrderDiscount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrderDiscounts indicates an expected call of ListOrderDiscounts.
+func (mr *MockStoreMockRecorder) ListOrderDiscounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrderDiscounts", reflect.TypeOf((*MockStore)(nil).ListOrderDiscounts), arg0, arg1)
+}
+
+// ListOrderItems mocks base method.
+func (m *MockStore) ListOrderItems(arg0 context.Context, arg1 int64) ([]db.OrderItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrderItems", arg0, arg1)
+	ret0, _ := ret[0].([]db.OrderItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrderItems indicates an expected call of ListOrderItems.
+func (mr *MockStoreMockRecorder) ListOrderItems(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrderItems", reflect.TypeOf((*MockStore)(nil).ListOrderItems), arg0, arg1)
+}
+
+// ListOrderRefunds mocks base method.
+func (m *MockStore) ListOrderRefunds(arg0 context.Context, arg1 int64) ([]db.OrderRefund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrderRefunds", arg0, arg1)
+	ret0, _ := ret[0].([]db.OrderRefund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrderRefunds indicates an expected call of ListOrderRefunds.
+func (mr *MockStoreMockRecorder) ListOrderRefunds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrderRefunds", reflect.TypeOf((*MockStore)(nil).ListOrderRefunds), arg0, arg1)
+}
+
+// ListPokemonData mocks base method.
+func (m *MockStore) ListPokemonData(arg0 context.Context, arg1 db.ListPokemonDataParams) ([]db.PokeProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPokemonData", arg0, arg1)
+	ret0, _ := ret[0].([]db.PokeProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPokemonData indicates an expected call of ListPokemonData.
+func (mr *MockStoreMockRecorder) ListPokemonData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPokemonData", reflect.TypeOf((*MockStore)(nil).ListPokemonData), arg0, arg1)
+}
+
+// ListPokemonOrderData mocks base method.
+func (m *MockStore) ListPokemonOrderData(arg0 context.Context, arg1 db.ListPokemonOrderDataParams) ([]db.PokeOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPokemonOrderData", arg0, arg1)
+	ret0, _ := ret[0].([]db.PokeOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPokemonOrderData indicates an expected call of ListPokemonOrderData.
+func (mr *MockStoreMockRecorder) ListPokemonOrderData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPokemonOrderData", reflect.TypeOf((*MockStore)(nil).ListPokemonOrderData), arg0, arg1)
+}
+
+// ListPromotions mocks base method.
+func (m *MockStore) ListPromotions(arg0 context.Context, arg1 db.ListPromotionsParams) ([]db.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromotions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromotions indicates an expected call of ListPromotions.
+func (mr *MockStoreMockRecorder) ListPromotions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotions", reflect.TypeOf((*MockStore)(nil).ListPromotions), arg0, arg1)
+}
+
+// ListUserAccount mocks base method.
+func (m *MockStore) ListUserAccount(arg0 context.Context, arg1 db.ListUserAccountParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserAccount", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserAccount indicates an expected call of ListUserAccount.
+func (mr *MockStoreMockRecorder) ListUserAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserAccount", reflect.TypeOf((*MockStore)(nil).ListUserAccount), arg0, arg1)
+}
+
+// ListUserAccountByName mocks base method.
+func (m *MockStore) ListUserAccountByName(arg0 context.Context, arg1 db.ListUserAccountByNameParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserAccountByName", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserAccountByName indicates an expected call of ListUserAccountByName.
+func (mr *MockStoreMockRecorder) ListUserAccountByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserAccountByName", reflect.TypeOf((*MockStore)(nil).ListUserAccountByName), arg0, arg1)
+}
+
+// LockLoginFailure mocks base method.
+func (m *MockStore) LockLoginFailure(arg0 context.Context, arg1 db.LockLoginFailureParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLoginFailure indicates an expected call of LockLoginFailure.
+func (mr *MockStoreMockRecorder) LockLoginFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLoginFailure", reflect.TypeOf((*MockStore)(nil).LockLoginFailure), arg0, arg1)
+}
+
+// OrderTx mocks base method.
+func (m *MockStore) OrderTx(arg0 context.Context, arg1 db.OrderTxParams) (db.OrderTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrderTx", arg0, arg1)
+	ret0, _ := ret[0].(db.OrderTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OrderTx indicates an expected call of OrderTx.
+func (mr *MockStoreMockRecorder) OrderTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderTx", reflect.TypeOf((*MockStore)(nil).OrderTx), arg0, arg1)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockStore) RecordLoginFailure(arg0 context.Context, arg1 db.RecordLoginFailureParams) (db.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockStoreMockRecorder) RecordLoginFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockStore)(nil).RecordLoginFailure), arg0, arg1)
+}
+
+// RedeemPromotion mocks base method.
+func (m *MockStore) RedeemPromotion(arg0 context.Context, arg1 int64) (db.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemPromotion", arg0, arg1)
+	ret0, _ := ret[0].(db.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemPromotion indicates an expected call of RedeemPromotion.
+func (mr *MockStoreMockRecorder) RedeemPromotion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemPromotion", reflect.TypeOf((*MockStore)(nil).RedeemPromotion), arg0, arg1)
+}
+
+// RefundOrderItem mocks base method.
+func (m *MockStore) RefundOrderItem(arg0 context.Context, arg1 db.RefundOrderItemParams) (db.OrderItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundOrderItem", arg0, arg1)
+	ret0, _ := ret[0].(db.OrderItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundOrderItem indicates an expected call of RefundOrderItem.
+func (mr *MockStoreMockRecorder) RefundOrderItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundOrderItem", reflect.TypeOf((*MockStore)(nil).RefundOrderItem), arg0, arg1)
+}
+
+// RefundOrderTx mocks base method.
+func (m *MockStore) RefundOrderTx(arg0 context.Context, arg1 db.RefundOrderTxParams) (db.RefundOrderTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundOrderTx", arg0, arg1)
+	ret0, _ := ret[0].(db.RefundOrderTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundOrderTx indicates an expected call of RefundOrderTx.
+func (mr *MockStoreMockRecorder) RefundOrderTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundOrderTx", reflect.TypeOf((*MockStore)(nil).RefundOrderTx), arg0, arg1)
+}
+
+// ReleaseExpiredReservationsTx mocks base method.
+func (m *MockStore) ReleaseExpiredReservationsTx(arg0 context.Context, arg1 db.ReleaseExpiredReservationsTxParams) (db.ReleaseExpiredReservationsTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpiredReservationsTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReleaseExpiredReservationsTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpiredReservationsTx indicates an expected call of ReleaseExpiredReservationsTx.
+func (mr *MockStoreMockRecorder) ReleaseExpiredReservationsTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpiredReservationsTx", reflect.TypeOf((*MockStore)(nil).ReleaseExpiredReservationsTx), arg0, arg1)
+}
+
+// ReleasePokemonStockData mocks base method.
+func (m *MockStore) ReleasePokemonStockData(arg0 context.Context, arg1 db.ReleasePokemonStockDataParams) (db.PokeProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleasePokemonStockData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleasePokemonStockData indicates an expected call of ReleasePokemonStockData.
+func (mr *MockStoreMockRecorder) ReleasePokemonStockData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleasePokemonStockData", reflect.TypeOf((*MockStore)(nil).ReleasePokemonStockData), arg0, arg1)
+}
+
+// ReleaseReservationTx mocks base method.
+func (m *MockStore) ReleaseReservationTx(arg0 context.Context, arg1 db.ReservationTxParams) (db.ReservationTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReservationTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReservationTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseReservationTx indicates an expected call of ReleaseReservationTx.
+func (mr *MockStoreMockRecorder) ReleaseReservationTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReservationTx", reflect.TypeOf((*MockStore)(nil).ReleaseReservationTx), arg0, arg1)
+}
+
+// ReservePokemonStockData mocks base method.
+func (m *MockStore) ReservePokemonStockData(arg0 context.Context, arg1 db.ReservePokemonStockDataParams) (db.PokeProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReservePokemonStockData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReservePokemonStockData indicates an expected call of ReservePokemonStockData.
+func (mr *MockStoreMockRecorder) ReservePokemonStockData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReservePokemonStockData", reflect.TypeOf((*MockStore)(nil).ReservePokemonStockData), arg0, arg1)
+}
+
+// ReserveStockTx mocks base method.
+func (m *MockStore) ReserveStockTx(arg0 context.Context, arg1 db.ReserveStockTxParams) (db.ReservationTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveStockTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReservationTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveStockTx indicates an expected call of ReserveStockTx.
+func (mr *MockStoreMockRecorder) ReserveStockTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveStockTx", reflect.TypeOf((*MockStore)(nil).ReserveStockTx), arg0, arg1)
+}
+
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(arg0 context.Context, arg1 db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ResetPasswordTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), arg0, arg1)
+}
+
+// RevokeApiKey mocks base method.
+func (m *MockStore) RevokeApiKey(arg0 context.Context, arg1 db.RevokeApiKeyParams) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeApiKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeApiKey indicates an expected call of RevokeApiKey.
+func (mr *MockStoreMockRecorder) RevokeApiKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeApiKey", reflect.TypeOf((*MockStore)(nil).RevokeApiKey), arg0, arg1)
+}
+
+// SaveIdempotencyResponse mocks base method.
+func (m *MockStore) SaveIdempotencyResponse(arg0 context.Context, arg1 db.SaveIdempotencyResponseParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdempotencyResponse", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdempotencyResponse indicates an expected call of SaveIdempotencyResponse.
+func (mr *MockStoreMockRecorder) SaveIdempotencyResponse(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdempotencyResponse", reflect.TypeOf((*MockStore)(nil).SaveIdempotencyResponse), arg0, arg1)
+}
+
+// UpdateAccountDisabled mocks base method.
+func (m *MockStore) UpdateAccountDisabled(arg0 context.Context, arg1 db.UpdateAccountDisabledParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountDisabled", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountDisabled indicates an expected call of UpdateAccountDisabled.
+func (mr *MockStoreMockRecorder) UpdateAccountDisabled(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountDisabled", reflect.TypeOf((*MockStore)(nil).UpdateAccountDisabled), arg0, arg1)
+}
+
+// UpdateAccountPassword mocks base method.
+func (m *MockStore) UpdateAccountPassword(arg0 context.Context, arg1 db.UpdateAccountPasswordParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountPassword", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountPassword indicates an expected call of UpdateAccountPassword.
+func (mr *MockStoreMockRecorder) UpdateAccountPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountPassword", reflect.TypeOf((*MockStore)(nil).UpdateAccountPassword), arg0, arg1)
+}
+
+// UpdateAccountTotp mocks base method.
+func (m *MockStore) UpdateAccountTotp(arg0 context.Context, arg1 db.UpdateAccountTotpParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountTotp", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountTotp indicates an expected call of UpdateAccountTotp.
+func (mr *MockStoreMockRecorder) UpdateAccountTotp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountTotp", reflect.TypeOf((*MockStore)(nil).UpdateAccountTotp), arg0, arg1)
+}
+
+// UpdateApiKeyLastUsed mocks base method.
+func (m *MockStore) UpdateApiKeyLastUsed(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateApiKeyLastUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateApiKeyLastUsed indicates an expected call of UpdateApiKeyLastUsed.
+func (mr *MockStoreMockRecorder) UpdateApiKeyLastUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateApiKeyLastUsed", reflect.TypeOf((*MockStore)(nil).UpdateApiKeyLastUsed), arg0, arg1)
+}
+
+// UpdateCartItem mocks base method.
+func (m *MockStore) UpdateCartItem(arg0 context.Context, arg1 db.UpdateCartItemParams) (db.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCartItem", arg0, arg1)
+	ret0, _ := ret[0].(db.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCartItem indicates an expected call of UpdateCartItem.
+func (mr *MockStoreMockRecorder) UpdateCartItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCartItem", reflect.TypeOf((*MockStore)(nil).UpdateCartItem), arg0, arg1)
+}
+
+// UpdateOrderStatusTx mocks base method.
+func (m *MockStore) UpdateOrderStatusTx(arg0 context.Context, arg1 db.UpdateOrderStatusTxParams) (db.OrderTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatusTx", arg0, arg1)
+	ret0, _ := ret[0].(db.OrderTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrderStatusTx indicates an expected call of UpdateOrderStatusTx.
+func (mr *MockStoreMockRecorder) UpdateOrderStatusTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatusTx", reflect.TypeOf((*MockStore)(nil).UpdateOrderStatusTx), arg0, arg1)
+}
+
+// UpdatePokemonData mocks base method.
+func (m *MockStore) UpdatePokemonData(arg0 context.Context, arg1 db.UpdatePokemonDataParams) (db.PokeProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePokemonData", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePokemonData indicates an expected call of UpdatePokemonData.
+func (mr *MockStoreMockRecorder) UpdatePokemonData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePokemonData", reflect.TypeOf((*MockStore)(nil).UpdatePokemonData), arg0, arg1)
+}
+
+// UpdatePokemonOrderStatus mocks base method.
+func (m *MockStore) UpdatePokemonOrderStatus(arg0 context.Context, arg1 db.UpdatePokemonOrderStatusParams) (db.PokeOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePokemonOrderStatus", arg0, arg1)
+	ret0, _ := ret[0].(db.PokeOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePokemonOrderStatus indicates an expected call of UpdatePokemonOrderStatus.
+func (mr *MockStoreMockRecorder) UpdatePokemonOrderStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePokemonOrderStatus", reflect.TypeOf((*MockStore)(nil).UpdatePokemonOrderStatus), arg0, arg1)
+}
+
+// UpdateStockReservationStatus mocks base method.
+func (m *MockStore) UpdateStockReservationStatus(arg0 context.Context, arg1 db.UpdateStockReservationStatusParams) (db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStockReservationStatus", arg0, arg1)
+	ret0, _ := ret[0].(db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStockReservationStatus indicates an expected call of UpdateStockReservationStatus.
+func (mr *MockStoreMockRecorder) UpdateStockReservationStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStockReservationStatus", reflect.TypeOf((*MockStore)(nil).UpdateStockReservationStatus), arg0, arg1)
+}
+
+// UpdateUserAccountRole mocks base method.
+func (m *MockStore) UpdateUserAccountRole(arg0 context.Context, arg1 db.UpdateUserAccountRoleParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserAccountRole", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserAccountRole indicates an expected call of UpdateUserAccountRole.
+func (mr *MockStoreMockRecorder) UpdateUserAccountRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserAccountRole", reflect.TypeOf((*MockStore)(nil).UpdateUserAccountRole), arg0, arg1)
+}
+
+// UpsertAccountRevocation mocks base method.
+func (m *MockStore) UpsertAccountRevocation(arg0 context.Context, arg1 db.UpsertAccountRevocationParams) (db.AccountRevocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAccountRevocation", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountRevocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertAccountRevocation indicates an expected call of UpsertAccountRevocation.
+func (mr *MockStoreMockRecorder) UpsertAccountRevocation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAccountRevocation", reflect.TypeOf((*MockStore)(nil).UpsertAccountRevocation), arg0, arg1)
+}
+
+// UseAccountRecoveryCode mocks base method.
+func (m *MockStore) UseAccountRecoveryCode(arg0 context.Context, arg1 db.UseAccountRecoveryCodeParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAccountRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAccountRecoveryCode indicates an expected call of UseAccountRecoveryCode.
+func (mr *MockStoreMockRecorder) UseAccountRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAccountRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseAccountRecoveryCode), arg0, arg1)
+}
+
+// UseAccountTotpStep mocks base method.
+func (m *MockStore) UseAccountTotpStep(arg0 context.Context, arg1 db.UseAccountTotpStepParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAccountTotpStep", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAccountTotpStep indicates an expected call of UseAccountTotpStep.
+func (mr *MockStoreMockRecorder) UseAccountTotpStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAccountTotpStep", reflect.TypeOf((*MockStore)(nil).UseAccountTotpStep), arg0, arg1)
+}
+
+// UsePasswordReset mocks base method.
+func (m *MockStore) UsePasswordReset(arg0 context.Context, arg1 string) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsePasswordReset indicates an expected call of UsePasswordReset.
+func (mr *MockStoreMockRecorder) UsePasswordReset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordReset", reflect.TypeOf((*MockStore)(nil).UsePasswordReset), arg0, arg1)
 }
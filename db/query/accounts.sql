@@ -7,4 +7,35 @@ INSERT INTO accounts (
 
 -- name: GetAccountLog :one
 SELECT * FROM accounts
-WHERE username = $1 LIMIT 1;  
\ No newline at end of file
+WHERE username = $1 LIMIT 1;  
+-- name: UpdateAccountPassword :one
+UPDATE accounts
+SET hashed_password = $2, password_changet_at = $3
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateAccountDisabled :one
+UPDATE accounts
+SET is_disabled = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateAccountTotp :one
+UPDATE accounts
+SET totp_secret = $2, totp_enabled = $3, recovery_codes = $4
+WHERE username = $1
+RETURNING *;
+
+-- name: UseAccountRecoveryCode :one
+-- no row is returned when the recovery code is not left on the account, so a code is only used once
+UPDATE accounts
+SET recovery_codes = array_remove(recovery_codes, sqlc.arg(recovery_code)::varchar)
+WHERE username = sqlc.arg(username) AND sqlc.arg(recovery_code)::varchar = ANY(recovery_codes)
+RETURNING *;
+
+-- name: UseAccountTotpStep :one
+-- no row is returned when a code of the same or a later time step was already accepted, so a code is only used once
+UPDATE accounts
+SET totp_last_step = sqlc.arg(step)
+WHERE username = sqlc.arg(username) AND totp_last_step < sqlc.arg(step)
+RETURNING *;
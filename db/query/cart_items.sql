@@ -0,0 +1,29 @@
+-- name: AddCartItem :one
+INSERT INTO cart_items (
+    user_id, product_id, quantity
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (user_id, product_id) DO UPDATE
+SET quantity = cart_items.quantity + EXCLUDED.quantity,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListCartItems :many
+SELECT * FROM cart_items
+WHERE user_id = $1
+ORDER BY product_id;
+
+-- name: UpdateCartItem :one
+UPDATE cart_items
+SET quantity = $3, updated_at = now()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteCartItem :one
+DELETE FROM cart_items
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: ClearCart :exec
+DELETE FROM cart_items
+WHERE user_id = $1;
@@ -0,0 +1,30 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    username, idempotency_key, request_hash
+) VALUES (
+    sqlc.arg(username), sqlc.arg(idempotency_key), sqlc.arg(request_hash)
+) ON CONFLICT (username, idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    response_status = 0,
+    response_body = '',
+    created_at = now()
+WHERE idempotency_keys.created_at < sqlc.arg(expired_before)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND idempotency_key = $2 LIMIT 1;
+
+-- name: SaveIdempotencyResponse :exec
+UPDATE idempotency_keys
+SET response_status = $3, response_body = $4
+WHERE username = $1 AND idempotency_key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE username = $1 AND idempotency_key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+-- the keys created before expired_before are not replayed anymore
+DELETE FROM idempotency_keys
+WHERE created_at < sqlc.arg(expired_before);
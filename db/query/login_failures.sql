@@ -0,0 +1,26 @@
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (
+    scope, subject, failed_count, last_failed_at
+) VALUES (
+    $1, $2, 1, now()
+) ON CONFLICT (scope, subject) DO UPDATE
+SET failed_count = CASE
+        WHEN login_failures.last_failed_at < now() - interval '24 hours' THEN 1
+        ELSE login_failures.failed_count + 1
+    END,
+    last_failed_at = now()
+RETURNING *;
+
+-- name: LockLoginFailure :exec
+UPDATE login_failures
+SET locked_until = $3
+WHERE scope = $1 AND subject = $2;
+
+-- name: GetLoginLockedUntil :one
+SELECT COALESCE(MAX(locked_until), '0001-01-01')::timestamptz AS locked_until FROM login_failures
+WHERE (scope = 'username' AND subject = sqlc.arg(username))
+   OR (scope = 'ip' AND subject = sqlc.arg(client_ip));
+
+-- name: DeleteLoginFailure :exec
+DELETE FROM login_failures
+WHERE scope = $1 AND subject = $2;
@@ -0,0 +1,11 @@
+-- name: CreateOrderDiscount :one
+INSERT INTO order_discounts (
+    order_id, order_item_id, promotion_id, code, amount
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListOrderDiscounts :many
+SELECT * FROM order_discounts
+WHERE order_id = $1
+ORDER BY id;
@@ -0,0 +1,17 @@
+-- name: CreateOrderItem :one
+INSERT INTO order_items (
+    order_id, product_id, product_name, quantity, unit_price, currency, total_price, discount_amount
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListOrderItems :many
+SELECT * FROM order_items
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: RefundOrderItem :one
+UPDATE order_items
+SET refunded_quantity = refunded_quantity + sqlc.arg(quantity)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,11 @@
+-- name: CreateOrderRefund :one
+INSERT INTO order_refunds (
+    order_id, order_item_id, product_id, quantity, amount, reason, order_status, refunded_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListOrderRefunds :many
+SELECT * FROM order_refunds
+WHERE order_id = $1
+ORDER BY id;
@@ -1,19 +1,10 @@
 -- name: InsertPokemonOrderData :one
 INSERT INTO poke_orders (
-    user_id, product_id,quantity,total_price,order_detail
+    user_id, product_id,quantity,total_price,currency,discount_total
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
--- name: ListPokemonOrderData :many
-SELECT * FROM poke_orders
--- WHERE 
---     user_id = $1 OR
---     product_id = $2
-ORDER BY id
-LIMIT $1
-OFFSET $2;
-
 -- name: CancelPokemonOrderData :exec
 DELETE FROM poke_orders
 WHERE id = $1;
@@ -22,11 +13,26 @@ WHERE id = $1;
 SELECT * FROM poke_orders
 WHERE id = $1 LIMIT 1;
 
--- name: ListOrderDetailedData :many
-select poke_orders.id, users.user_name, poke_products.poke_name, poke_orders.quantity , poke_orders.total_price , poke_orders.order_detail 
-FROM ((poke_orders
-inner join users on poke_orders.user_id  = users.id)
-inner join poke_products on poke_orders.product_id = poke_products.id)
-order by id
-limit $1
-OFFSET $2;
+-- name: GetPokemonOrderDataForUpdate :one
+SELECT * FROM poke_orders
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: UpdatePokemonOrderStatus :one
+UPDATE poke_orders
+SET status = $2,
+    paid_at = CASE WHEN $2 = 'paid' THEN now() ELSE paid_at END,
+    fulfilled_at = CASE WHEN $2 = 'fulfilled' THEN now() ELSE fulfilled_at END,
+    cancelled_at = CASE WHEN $2 = 'cancelled' THEN now() ELSE cancelled_at END,
+    refunded_at = CASE WHEN $2 = 'refunded' THEN now() ELSE refunded_at END,
+    refunded_total = CASE WHEN $2 IN ('cancelled', 'refunded') THEN total_price ELSE refunded_total END,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: AddPokemonOrderRefundedTotal :one
+UPDATE poke_orders
+SET refunded_total = refunded_total + sqlc.arg(amount),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -1,18 +1,23 @@
 -- name: CreatePokemonData :one
 INSERT INTO poke_products (
-    poke_name,status,poke_price,poke_stock
+    poke_name,status,poke_price,poke_stock,currency,poke_type
 ) VALUES (
-	$1, $2, $3, $4
+	$1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetPokemonData :one
 SELECT * FROM poke_products
 WHERE id = $1 LIMIT 1;
 
+-- name: GetPokemonDataForUpdate :one
+SELECT * FROM poke_products
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: DeductPokemonStockData :one
 UPDATE poke_products
 SET poke_stock = poke_stock - sqlc.arg(amount)
-WHERE id = sqlc.arg(id)
+WHERE id = sqlc.arg(id) AND poke_stock >= sqlc.arg(amount)
 RETURNING *;
 
 -- name: AddPokemonStockData :one
@@ -22,14 +27,38 @@ WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: ListPokemonData :many
+-- a null after_id starts from the first pokemon
 SELECT * FROM poke_products
+WHERE sqlc.narg(after_id)::bigint IS NULL OR id > sqlc.narg(after_id)
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: UpdatePokemonData :one
+-- a null poke_type keeps the type of the pokemon
+UPDATE poke_products
+SET status = sqlc.arg(status), poke_price = sqlc.arg(poke_price), poke_stock = sqlc.arg(poke_stock),
+    poke_type = COALESCE(sqlc.narg(poke_type), poke_type)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+
+-- name: ReservePokemonStockData :one
 UPDATE poke_products
-SET status = $2, poke_price = $3, poke_stock = $4
-WHERE id = $1
+SET poke_stock = poke_stock - sqlc.arg(amount),
+    reserved_stock = reserved_stock + sqlc.arg(amount)
+WHERE id = sqlc.arg(id) AND poke_stock >= sqlc.arg(amount)
 RETURNING *;
 
+-- name: ReleasePokemonStockData :one
+UPDATE poke_products
+SET poke_stock = poke_stock + sqlc.arg(amount),
+    reserved_stock = reserved_stock - sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ClaimReservedStockData :one
+UPDATE poke_products
+SET reserved_stock = reserved_stock - sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
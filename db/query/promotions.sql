@@ -0,0 +1,42 @@
+-- name: CreatePromotion :one
+INSERT INTO promotions (
+    code, description, discount_type, discount_value, currency, product_id, poke_type, user_role,
+    starts_at, ends_at, max_redemptions, max_redemptions_per_user, created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING *;
+
+-- name: GetPromotion :one
+SELECT * FROM promotions
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPromotionByCodeForUpdate :one
+SELECT * FROM promotions
+WHERE code = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPromotions :many
+-- a null after_id starts from the first promotion
+SELECT * FROM promotions
+WHERE sqlc.narg(after_id)::bigint IS NULL OR id > sqlc.narg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: RedeemPromotion :one
+-- no row is returned once the promotion reached its max redemptions
+UPDATE promotions
+SET redemption_count = redemption_count + 1
+WHERE id = $1 AND (max_redemptions IS NULL OR redemption_count < max_redemptions)
+RETURNING *;
+
+-- name: CreatePromotionRedemption :one
+INSERT INTO promotion_redemptions (
+    promotion_id, user_id, order_id
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: CountUserPromotionRedemptions :one
+SELECT count(*) FROM promotion_redemptions
+WHERE promotion_id = $1 AND user_id = $2;
@@ -0,0 +1,30 @@
+-- name: CreateRevokedToken :exec
+INSERT INTO revoked_tokens (
+    id, username, expires_at
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (id) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1 FROM revoked_tokens
+    WHERE id = $1
+);
+
+-- name: DeleteExpiredRevokedTokens :exec
+DELETE FROM revoked_tokens
+WHERE expires_at < now();
+
+-- name: UpsertAccountRevocation :one
+INSERT INTO account_revocations (
+    username, revoked_before
+) VALUES (
+    $1, $2
+) ON CONFLICT (username) DO UPDATE
+SET revoked_before = GREATEST(account_revocations.revoked_before, EXCLUDED.revoked_before),
+    updated_at = now()
+RETURNING *;
+
+-- name: GetAccountRevocation :one
+SELECT * FROM account_revocations
+WHERE username = $1 LIMIT 1;
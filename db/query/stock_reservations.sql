@@ -0,0 +1,28 @@
+-- name: CreateStockReservation :one
+INSERT INTO stock_reservations (
+    user_id, product_id, quantity, expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetStockReservation :one
+SELECT * FROM stock_reservations
+WHERE id = $1 LIMIT 1;
+
+-- name: GetStockReservationForUpdate :one
+SELECT * FROM stock_reservations
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListExpiredStockReservations :many
+SELECT * FROM stock_reservations
+WHERE status = 'active' AND expires_at <= sqlc.arg(now)
+ORDER BY product_id, id
+LIMIT sqlc.arg(limit_count)
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateStockReservationStatus :one
+UPDATE stock_reservations
+SET status = $2, order_id = $3, updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -10,10 +10,12 @@ SELECT * FROM users
 WHERE id = $1 LIMIT 1;
 
 -- name: ListUserAccount :many
+-- a null after_id starts from the first user
 SELECT * FROM users
+WHERE sqlc.narg(after_id)::bigint IS NULL OR id > sqlc.narg(after_id)
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: UpdateUserAccountRole :one
 UPDATE users
@@ -23,4 +25,12 @@ RETURNING *;
 
 -- name: DeleteUserAccount :exec
 DELETE FROM users
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListUserAccountByName :many
+SELECT * FROM users
+WHERE user_name = sqlc.arg(user_name)
+    AND (sqlc.narg(after_id)::bigint IS NULL OR id > sqlc.narg(after_id))
+ORDER BY id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
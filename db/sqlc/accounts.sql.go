@@ -5,6 +5,9 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createAccountLog = `-- name: CreateAccountLog :one
@@ -12,7 +15,7 @@ INSERT INTO accounts (
     username, hashed_password, full_name
 ) VALUES (
     $1, $2, $3
-) RETURNING username, hashed_password, full_name, created_at, password_changet_at
+) RETURNING username, hashed_password, full_name, created_at, password_changet_at, is_disabled, totp_secret, totp_enabled, recovery_codes, totp_last_step
 `
 
 type CreateAccountLogParams struct {
@@ -30,12 +33,17 @@ func (q *Queries) CreateAccountLog(ctx context.Context, arg CreateAccountLogPara
 		&i.FullName,
 		&i.CreatedAt,
 		&i.PasswordChangetAt,
+		&i.IsDisabled,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		pq.Array(&i.RecoveryCodes),
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
 const getAccountLog = `-- name: GetAccountLog :one
-SELECT username, hashed_password, full_name, created_at, password_changet_at FROM accounts
+SELECT username, hashed_password, full_name, created_at, password_changet_at, is_disabled, totp_secret, totp_enabled, recovery_codes, totp_last_step FROM accounts
 WHERE username = $1 LIMIT 1
 `
 
@@ -48,6 +56,171 @@ func (q *Queries) GetAccountLog(ctx context.Context, username string) (Account,
 		&i.FullName,
 		&i.CreatedAt,
 		&i.PasswordChangetAt,
+		&i.IsDisabled,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		pq.Array(&i.RecoveryCodes),
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updateAccountDisabled = `-- name: UpdateAccountDisabled :one
+UPDATE accounts
+SET is_disabled = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, created_at, password_changet_at, is_disabled, totp_secret, totp_enabled, recovery_codes, totp_last_step
+`
+
+type UpdateAccountDisabledParams struct {
+	Username   string `json:"username"`
+	IsDisabled bool   `json:"is_disabled"`
+}
+
+func (q *Queries) UpdateAccountDisabled(ctx context.Context, arg UpdateAccountDisabledParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountDisabled, arg.Username, arg.IsDisabled)
+	var i Account
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.PasswordChangetAt,
+		&i.IsDisabled,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		pq.Array(&i.RecoveryCodes),
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updateAccountPassword = `-- name: UpdateAccountPassword :one
+UPDATE accounts
+SET hashed_password = $2, password_changet_at = $3
+WHERE username = $1
+RETURNING username, hashed_password, full_name, created_at, password_changet_at, is_disabled, totp_secret, totp_enabled, recovery_codes, totp_last_step
+`
+
+type UpdateAccountPasswordParams struct {
+	Username          string       `json:"username"`
+	HashedPassword    string       `json:"hashed_password"`
+	PasswordChangetAt sql.NullTime `json:"password_changet_at"`
+}
+
+func (q *Queries) UpdateAccountPassword(ctx context.Context, arg UpdateAccountPasswordParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountPassword, arg.Username, arg.HashedPassword, arg.PasswordChangetAt)
+	var i Account
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.PasswordChangetAt,
+		&i.IsDisabled,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		pq.Array(&i.RecoveryCodes),
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const updateAccountTotp = `-- name: UpdateAccountTotp :one
+UPDATE accounts
+SET totp_secret = $2, totp_enabled = $3, recovery_codes = $4
+WHERE username = $1
+RETURNING username, hashed_password, full_name, created_at, password_changet_at, is_disabled, totp_secret, totp_enabled, recovery_codes, totp_last_step
+`
+
+type UpdateAccountTotpParams struct {
+	Username      string   `json:"username"`
+	TotpSecret    string   `json:"totp_secret"`
+	TotpEnabled   bool     `json:"totp_enabled"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (q *Queries) UpdateAccountTotp(ctx context.Context, arg UpdateAccountTotpParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountTotp,
+		arg.Username,
+		arg.TotpSecret,
+		arg.TotpEnabled,
+		pq.Array(arg.RecoveryCodes),
+	)
+	var i Account
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.PasswordChangetAt,
+		&i.IsDisabled,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		pq.Array(&i.RecoveryCodes),
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const useAccountRecoveryCode = `-- name: UseAccountRecoveryCode :one
+UPDATE accounts
+SET recovery_codes = array_remove(recovery_codes, $1::varchar)
+WHERE username = $2 AND $1::varchar = ANY(recovery_codes)
+RETURNING username, hashed_password, full_name, created_at, password_changet_at, is_disabled, totp_secret, totp_enabled, recovery_codes, totp_last_step
+`
+
+type UseAccountRecoveryCodeParams struct {
+	RecoveryCode string `json:"recovery_code"`
+	Username     string `json:"username"`
+}
+
+// no row is returned when the recovery code is not left on the account, so a code is only used once
+func (q *Queries) UseAccountRecoveryCode(ctx context.Context, arg UseAccountRecoveryCodeParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, useAccountRecoveryCode, arg.RecoveryCode, arg.Username)
+	var i Account
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.PasswordChangetAt,
+		&i.IsDisabled,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		pq.Array(&i.RecoveryCodes),
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const useAccountTotpStep = `-- name: UseAccountTotpStep :one
+UPDATE accounts
+SET totp_last_step = $1
+WHERE username = $2 AND totp_last_step < $1
+RETURNING username, hashed_password, full_name, created_at, password_changet_at, is_disabled, totp_secret, totp_enabled, recovery_codes, totp_last_step
+`
+
+type UseAccountTotpStepParams struct {
+	Step     int64  `json:"step"`
+	Username string `json:"username"`
+}
+
+// no row is returned when a code of the same or a later time step was already accepted, so a code is only used once
+func (q *Queries) UseAccountTotpStep(ctx context.Context, arg UseAccountTotpStepParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, useAccountTotpStep, arg.Step, arg.Username)
+	var i Account
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.PasswordChangetAt,
+		&i.IsDisabled,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		pq.Array(&i.RecoveryCodes),
+		&i.TotpLastStep,
 	)
 	return i, err
 }
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -50,3 +51,112 @@ func TestGetUserAccountLog(t *testing.T) {
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 
 }
+
+func TestUpdateAccountPassword(t *testing.T) {
+	account1 := mockCreateAccountLog(t)
+
+	hashedPasswd, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	changedAt := time.Now()
+	account2, err := testQueries.UpdateAccountPassword(context.Background(), UpdateAccountPasswordParams{
+		Username:          account1.Username,
+		HashedPassword:    hashedPasswd,
+		PasswordChangetAt: sql.NullTime{Time: changedAt, Valid: true},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Username, account2.Username)
+	require.Equal(t, hashedPasswd, account2.HashedPassword)
+	require.NotEqual(t, account1.HashedPassword, account2.HashedPassword)
+	require.WithinDuration(t, changedAt, account2.PasswordChangetAt.Time, time.Second)
+}
+
+func TestUpdateAccountDisabled(t *testing.T) {
+	account1 := mockCreateAccountLog(t)
+	require.False(t, account1.IsDisabled)
+
+	account2, err := testQueries.UpdateAccountDisabled(context.Background(), UpdateAccountDisabledParams{
+		Username:   account1.Username,
+		IsDisabled: true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Username, account2.Username)
+	require.True(t, account2.IsDisabled)
+}
+
+func TestUpdateAccountTotp(t *testing.T) {
+	account1 := mockCreateAccountLog(t)
+	require.False(t, account1.TotpEnabled)
+	require.Empty(t, account1.TotpSecret)
+	require.Empty(t, account1.RecoveryCodes)
+
+	secret, err := util.GenerateTotpSecret()
+	require.NoError(t, err)
+
+	arg := UpdateAccountTotpParams{
+		Username:      account1.Username,
+		TotpSecret:    secret,
+		TotpEnabled:   true,
+		RecoveryCodes: []string{util.HashRecoveryCode("abcde-12345")},
+	}
+	account2, err := testQueries.UpdateAccountTotp(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Username, account2.Username)
+	require.Equal(t, arg.TotpSecret, account2.TotpSecret)
+	require.True(t, account2.TotpEnabled)
+	require.Equal(t, arg.RecoveryCodes, account2.RecoveryCodes)
+}
+
+func TestUseAccountRecoveryCode(t *testing.T) {
+	account1 := mockCreateAccountLog(t)
+	codes := []string{util.HashRecoveryCode("abcde-12345"), util.HashRecoveryCode("fghij-67890")}
+
+	_, err := testQueries.UpdateAccountTotp(context.Background(), UpdateAccountTotpParams{
+		Username:      account1.Username,
+		TotpSecret:    util.RandomString(32),
+		TotpEnabled:   true,
+		RecoveryCodes: codes,
+	})
+	require.NoError(t, err)
+
+	arg := UseAccountRecoveryCodeParams{
+		RecoveryCode: codes[0],
+		Username:     account1.Username,
+	}
+	account2, err := testQueries.UseAccountRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, codes[1:], account2.RecoveryCodes)
+
+	// the code is single use
+	_, err = testQueries.UseAccountRecoveryCode(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestUseAccountTotpStep(t *testing.T) {
+	account1 := mockCreateAccountLog(t)
+	require.Zero(t, account1.TotpLastStep)
+
+	arg := UseAccountTotpStepParams{
+		Step:     time.Now().Unix() / 30,
+		Username: account1.Username,
+	}
+	account2, err := testQueries.UseAccountTotpStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Step, account2.TotpLastStep)
+
+	// the same step and an earlier one are replays
+	_, err = testQueries.UseAccountTotpStep(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	arg.Step--
+	_, err = testQueries.UseAccountTotpStep(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	arg.Step += 2
+	account2, err = testQueries.UseAccountTotpStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Step, account2.TotpLastStep)
+}
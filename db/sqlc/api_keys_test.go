@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func mockCreateApiKey(t *testing.T, user User) ApiKey {
+	key, prefix, err := util.GenerateApiKey()
+	require.NoError(t, err)
+
+	arg := CreateApiKeyParams{
+		Username:  user.UserName,
+		Name:      util.RandomString(8),
+		Prefix:    prefix,
+		HashedKey: util.HashSecret(key),
+		UserID:    user.ID,
+		Role:      user.UserRole,
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	apiKey, err := testQueries.CreateApiKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, apiKey)
+
+	require.Equal(t, arg.Username, apiKey.Username)
+	require.Equal(t, arg.Name, apiKey.Name)
+	require.Equal(t, arg.Prefix, apiKey.Prefix)
+	require.Equal(t, arg.HashedKey, apiKey.HashedKey)
+	require.Equal(t, arg.UserID, apiKey.UserID)
+	require.Equal(t, arg.Role, apiKey.Role)
+	require.WithinDuration(t, arg.ExpiresAt.Time, apiKey.ExpiresAt.Time, time.Second)
+	require.False(t, apiKey.LastUsedAt.Valid)
+	require.False(t, apiKey.RevokedAt.Valid)
+
+	require.NotZero(t, apiKey.ID)
+	require.NotZero(t, apiKey.CreatedAt)
+
+	return apiKey
+}
+
+func TestCreateApiKey(t *testing.T) {
+	mockCreateApiKey(t, mockCreateUserAccount(t))
+}
+
+func TestGetApiKeyByPrefix(t *testing.T) {
+	apiKey1 := mockCreateApiKey(t, mockCreateUserAccount(t))
+	apiKey2, err := testQueries.GetApiKeyByPrefix(context.Background(), apiKey1.Prefix)
+	require.NoError(t, err)
+
+	require.Equal(t, apiKey1.ID, apiKey2.ID)
+	require.Equal(t, apiKey1.HashedKey, apiKey2.HashedKey)
+}
+
+func TestListApiKeys(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	for i := 0; i < 3; i++ {
+		mockCreateApiKey(t, user)
+	}
+
+	apiKeys, err := testQueries.ListApiKeys(context.Background(), ListApiKeysParams{
+		Username: user.UserName,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 3)
+
+	for _, apiKey := range apiKeys {
+		require.Equal(t, user.UserName, apiKey.Username)
+	}
+
+	// the keys after the cursor are listed
+	next, err := testQueries.ListApiKeys(context.Background(), ListApiKeysParams{
+		Username: user.UserName,
+		AfterID:  sql.NullInt64{Int64: apiKeys[0].ID, Valid: true},
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, apiKeys[1:], next)
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	apiKey1 := mockCreateApiKey(t, mockCreateUserAccount(t))
+
+	arg := RevokeApiKeyParams{
+		ID:       apiKey1.ID,
+		Username: apiKey1.Username,
+	}
+
+	apiKey2, err := testQueries.RevokeApiKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, apiKey2.RevokedAt.Valid)
+
+	// revoking twice does not find the key anymore
+	_, err = testQueries.RevokeApiKey(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestUpdateApiKeyLastUsed(t *testing.T) {
+	apiKey1 := mockCreateApiKey(t, mockCreateUserAccount(t))
+
+	err := testQueries.UpdateApiKeyLastUsed(context.Background(), apiKey1.ID)
+	require.NoError(t, err)
+
+	apiKey2, err := testQueries.GetApiKeyByPrefix(context.Background(), apiKey1.Prefix)
+	require.NoError(t, err)
+	require.True(t, apiKey2.LastUsedAt.Valid)
+	require.WithinDuration(t, time.Now(), apiKey2.LastUsedAt.Time, time.Second)
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mockAddCartItem(t *testing.T, user User, pokemon PokeProduct, quantity int32) CartItem {
+	arg := AddCartItemParams{
+		UserID:    user.ID,
+		ProductID: pokemon.ID,
+		Quantity:  quantity,
+	}
+
+	item, err := testQueries.AddCartItem(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, item)
+
+	require.Equal(t, arg.UserID, item.UserID)
+	require.Equal(t, arg.ProductID, item.ProductID)
+	require.NotZero(t, item.ID)
+	require.NotZero(t, item.CreatedAt)
+
+	return item
+}
+
+func TestAddCartItem(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+
+	item := mockAddCartItem(t, user, pokemon, 2)
+	require.Equal(t, int32(2), item.Quantity)
+
+	// adding the same product again raises the quantity of the existing line
+	again := mockAddCartItem(t, user, pokemon, 3)
+	require.Equal(t, item.ID, again.ID)
+	require.Equal(t, int32(5), again.Quantity)
+}
+
+func TestListCartItems(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	for i := 0; i < 3; i++ {
+		mockAddCartItem(t, user, mockRandomData(t), 1)
+	}
+	mockAddCartItem(t, mockCreateUserAccount(t), mockRandomData(t), 1)
+
+	items, err := testQueries.ListCartItems(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	for _, item := range items {
+		require.Equal(t, user.ID, item.UserID)
+	}
+}
+
+func TestUpdateCartItem(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	item := mockAddCartItem(t, user, mockRandomData(t), 1)
+
+	updated, err := testQueries.UpdateCartItem(context.Background(), UpdateCartItemParams{
+		ID:       item.ID,
+		UserID:   user.ID,
+		Quantity: 7,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(7), updated.Quantity)
+
+	// the line of another user cannot be changed
+	_, err = testQueries.UpdateCartItem(context.Background(), UpdateCartItemParams{
+		ID:       item.ID,
+		UserID:   user.ID + 1000000,
+		Quantity: 1,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestDeleteCartItem(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	item := mockAddCartItem(t, user, mockRandomData(t), 1)
+
+	deleted, err := testQueries.DeleteCartItem(context.Background(), DeleteCartItemParams{
+		ID:     item.ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, item.ID, deleted.ID)
+
+	_, err = testQueries.DeleteCartItem(context.Background(), DeleteCartItemParams{
+		ID:     item.ID,
+		UserID: user.ID,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestClearCart(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	mockAddCartItem(t, user, mockRandomData(t), 1)
+	mockAddCartItem(t, user, mockRandomData(t), 1)
+
+	err := testQueries.ClearCart(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	items, err := testQueries.ListCartItems(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
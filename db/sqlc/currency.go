@@ -0,0 +1,9 @@
+package db
+
+import "errors"
+
+// DefaultCurrency is the currency of a product created without one, prices are in its minor units
+const DefaultCurrency = "USD"
+
+// ErrMixedCurrency is returned when the lines of a single order are priced in different currencies
+var ErrMixedCurrency = errors.New("order lines are priced in different currencies")
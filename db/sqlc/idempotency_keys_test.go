@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func mockCreateIdempotencyKey(t *testing.T, account Account) IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		Username:       account.Username,
+		IdempotencyKey: util.RandomString(16),
+		RequestHash:    util.HashSecret(util.RandomString(32)),
+		ExpiredBefore:  time.Now().Add(-24 * time.Hour),
+	}
+
+	record, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, record)
+
+	require.Equal(t, arg.Username, record.Username)
+	require.Equal(t, arg.IdempotencyKey, record.IdempotencyKey)
+	require.Equal(t, arg.RequestHash, record.RequestHash)
+	require.Zero(t, record.ResponseStatus)
+	require.Empty(t, record.ResponseBody)
+	require.NotZero(t, record.CreatedAt)
+
+	return record
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	record := mockCreateIdempotencyKey(t, mockCreateAccountLog(t))
+
+	// the key is taken until it expires
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:       record.Username,
+		IdempotencyKey: record.IdempotencyKey,
+		RequestHash:    record.RequestHash,
+		ExpiredBefore:  time.Now().Add(-24 * time.Hour),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// an expired key is taken over by the new request
+	renewed, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:       record.Username,
+		IdempotencyKey: record.IdempotencyKey,
+		RequestHash:    util.HashSecret(util.RandomString(32)),
+		ExpiredBefore:  time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, record.RequestHash, renewed.RequestHash)
+}
+
+func TestSaveIdempotencyResponse(t *testing.T) {
+	record := mockCreateIdempotencyKey(t, mockCreateAccountLog(t))
+
+	arg := SaveIdempotencyResponseParams{
+		Username:       record.Username,
+		IdempotencyKey: record.IdempotencyKey,
+		ResponseStatus: http.StatusOK,
+		ResponseBody:   []byte(`{"id":1}`),
+	}
+	err := testQueries.SaveIdempotencyResponse(context.Background(), arg)
+	require.NoError(t, err)
+
+	saved, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       record.Username,
+		IdempotencyKey: record.IdempotencyKey,
+	})
+	require.NoError(t, err)
+	require.Equal(t, arg.ResponseStatus, saved.ResponseStatus)
+	require.Equal(t, arg.ResponseBody, saved.ResponseBody)
+	require.Equal(t, record.RequestHash, saved.RequestHash)
+}
+
+func TestDeleteIdempotencyKey(t *testing.T) {
+	record := mockCreateIdempotencyKey(t, mockCreateAccountLog(t))
+
+	err := testQueries.DeleteIdempotencyKey(context.Background(), DeleteIdempotencyKeyParams{
+		Username:       record.Username,
+		IdempotencyKey: record.IdempotencyKey,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       record.Username,
+		IdempotencyKey: record.IdempotencyKey,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestSweepIdempotencyKeys(t *testing.T) {
+	store := NewStore(testDB)
+	record := mockCreateIdempotencyKey(t, mockCreateAccountLog(t))
+	arg := GetIdempotencyKeyParams{
+		Username:       record.Username,
+		IdempotencyKey: record.IdempotencyKey,
+	}
+
+	// a key within its ttl is kept
+	SweepIdempotencyKeys(context.Background(), store, record.CreatedAt.Add(-time.Minute))
+	_, err := testQueries.GetIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	SweepIdempotencyKeys(context.Background(), store, record.CreatedAt.Add(time.Minute))
+	_, err = testQueries.GetIdempotencyKey(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunIdempotencyKeySweeper deletes the idempotency keys older than the ttl every interval until the context is done,
+// a zero interval disables the sweeper
+func RunIdempotencyKeySweeper(ctx context.Context, store Store, interval time.Duration, ttl time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			SweepIdempotencyKeys(ctx, store, time.Now().Add(-ttl))
+		}
+	}
+}
+
+// SweepIdempotencyKeys deletes every idempotency key created before the given time,
+// such a key is taken over by the next request using it anyway
+func SweepIdempotencyKeys(ctx context.Context, store Store, expiredBefore time.Time) {
+	err := store.DeleteExpiredIdempotencyKeys(ctx, expiredBefore)
+	if err != nil {
+		log.Println("cannot delete expired idempotency keys:", err)
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func mockRecordLoginFailure(t *testing.T, subject string) LoginFailure {
+	arg := RecordLoginFailureParams{
+		Scope:   "username",
+		Subject: subject,
+	}
+
+	failure, err := testQueries.RecordLoginFailure(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, failure)
+
+	require.Equal(t, arg.Scope, failure.Scope)
+	require.Equal(t, arg.Subject, failure.Subject)
+	require.NotZero(t, failure.LastFailedAt)
+
+	return failure
+}
+
+func TestRecordLoginFailure(t *testing.T) {
+	subject := util.RandomUser()
+
+	failure1 := mockRecordLoginFailure(t, subject)
+	require.Equal(t, int32(1), failure1.FailedCount)
+	require.False(t, failure1.LockedUntil.Valid)
+
+	failure2 := mockRecordLoginFailure(t, subject)
+	require.Equal(t, int32(2), failure2.FailedCount)
+}
+
+func TestLockLoginFailure(t *testing.T) {
+	subject := util.RandomUser()
+	mockRecordLoginFailure(t, subject)
+
+	lockedUntil, err := testQueries.GetLoginLockedUntil(context.Background(), GetLoginLockedUntilParams{
+		Username: subject,
+		ClientIp: subject,
+	})
+	require.NoError(t, err)
+	require.True(t, lockedUntil.Before(time.Now()))
+
+	arg := LockLoginFailureParams{
+		Scope:       "username",
+		Subject:     subject,
+		LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+	err = testQueries.LockLoginFailure(context.Background(), arg)
+	require.NoError(t, err)
+
+	lockedUntil, err = testQueries.GetLoginLockedUntil(context.Background(), GetLoginLockedUntilParams{
+		Username: subject,
+		ClientIp: util.RandomUser(),
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, arg.LockedUntil.Time, lockedUntil, time.Second)
+}
+
+func TestDeleteLoginFailure(t *testing.T) {
+	subject := util.RandomUser()
+	mockRecordLoginFailure(t, subject)
+
+	err := testQueries.DeleteLoginFailure(context.Background(), DeleteLoginFailureParams{
+		Scope:   "username",
+		Subject: subject,
+	})
+	require.NoError(t, err)
+
+	failure := mockRecordLoginFailure(t, subject)
+	require.Equal(t, int32(1), failure.FailedCount)
+}
@@ -5,6 +5,8 @@ package db
 import (
 	"database/sql"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -13,29 +15,242 @@ type Account struct {
 	FullName          string       `json:"full_name"`
 	CreatedAt         time.Time    `json:"created_at"`
 	PasswordChangetAt sql.NullTime `json:"password_changet_at"`
+	// disabled accounts are not able to login
+	IsDisabled bool `json:"is_disabled"`
+	// base32 TOTP secret, empty until enrollment
+	TotpSecret string `json:"totp_secret"`
+	// login requires a TOTP code once enabled
+	TotpEnabled bool `json:"totp_enabled"`
+	// sha256 of the unused recovery codes
+	RecoveryCodes []string `json:"recovery_codes"`
+	// time step of the last accepted TOTP code, a code of the same or an earlier step is a replay
+	TotpLastStep int64 `json:"totp_last_step"`
 }
 
-type PokeOrder struct {
+type AccountRevocation struct {
+	Username string `json:"username"`
+	// every token issued before this time is rejected
+	RevokedBefore time.Time `json:"revoked_before"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ApiKey struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	// public part of the key used to look it up
+	Prefix string `json:"prefix"`
+	// sha256 of the whole key
+	HashedKey  string       `json:"hashed_key"`
+	UserID     int64        `json:"user_id"`
+	Role       string       `json:"role"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type CartItem struct {
 	ID        int64 `json:"id"`
 	UserID    int64 `json:"user_id"`
 	ProductID int64 `json:"product_id"`
 	// must be positive
-	Quantity int32 `json:"quantity"`
+	Quantity  int32     `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Username       string `json:"username"`
+	IdempotencyKey string `json:"idempotency_key"`
+	// sha256 of the method, path and body of the first request
+	RequestHash string `json:"request_hash"`
+	// 0 while the first request is running
+	ResponseStatus int32     `json:"response_status"`
+	ResponseBody   []byte    `json:"response_body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type LoginFailure struct {
+	// username or ip
+	Scope       string `json:"scope"`
+	Subject     string `json:"subject"`
+	FailedCount int32  `json:"failed_count"`
+	// login is refused until this time
+	LockedUntil  sql.NullTime `json:"locked_until"`
+	LastFailedAt time.Time    `json:"last_failed_at"`
+}
+
+type OrderDiscount struct {
+	ID          int64 `json:"id"`
+	OrderID     int64 `json:"order_id"`
+	OrderItemID int64 `json:"order_item_id"`
+	PromotionID int64 `json:"promotion_id"`
+	// code of the promotion when the order was placed
+	Code      string    `json:"code"`
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OrderItem struct {
+	ID        int64 `json:"id"`
+	OrderID   int64 `json:"order_id"`
+	ProductID int64 `json:"product_id"`
+	Quantity  int32 `json:"quantity"`
+	// price of the product when the order was placed
+	UnitPrice int64 `json:"unit_price"`
+	// quantity times the unit price, minus the discount amount
+	TotalPrice int64     `json:"total_price"`
+	CreatedAt  time.Time `json:"created_at"`
+	// returned by partial refunds
+	RefundedQuantity int32 `json:"refunded_quantity"`
+	// name of the product when the order was placed
+	ProductName string `json:"product_name"`
+	// currency of the unit price when the order was placed
+	Currency string `json:"currency"`
+	// taken off the line by the promotion of the order
+	DiscountAmount int64 `json:"discount_amount"`
+}
+
+type OrderRefund struct {
+	ID          int64 `json:"id"`
+	OrderID     int64 `json:"order_id"`
+	OrderItemID int64 `json:"order_item_id"`
+	ProductID   int64 `json:"product_id"`
+	Quantity    int32 `json:"quantity"`
+	// share of the total price of the order item for the quantity
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+	// status of the order when it was refunded, a pending order was only partially cancelled
+	OrderStatus string `json:"order_status"`
+	// username of the account which made the refund
+	RefundedBy string    `json:"refunded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type PasswordReset struct {
+	// sha256 of the reset token
+	HashedToken string    `json:"hashed_token"`
+	Username    string    `json:"username"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	// a reset token works a single time
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type PokeOrder struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// only set on single product orders, the lines are on order_items
+	ProductID sql.NullInt64 `json:"product_id"`
 	// must be positive
-	TotalPrice  int64     `json:"total_price"`
-	OrderDetail string    `json:"order_detail"`
-	CreatedAt   time.Time `json:"created_at"`
+	Quantity int32 `json:"quantity"`
+	// paid for the order, after its discounts
+	TotalPrice int64     `json:"total_price"`
+	CreatedAt  time.Time `json:"created_at"`
+	// pending, paid, fulfilled, cancelled or refunded
+	Status      string       `json:"status"`
+	PaidAt      sql.NullTime `json:"paid_at"`
+	FulfilledAt sql.NullTime `json:"fulfilled_at"`
+	CancelledAt sql.NullTime `json:"cancelled_at"`
+	RefundedAt  sql.NullTime `json:"refunded_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	// given back by refunds, the whole total once cancelled or refunded
+	RefundedTotal int64 `json:"refunded_total"`
+	// total price minus the refunded total
+	NetTotal int64 `json:"net_total"`
+	// ISO 4217 code of every price of the order
+	Currency string `json:"currency"`
+	// taken off the order by its promotion
+	DiscountTotal int64 `json:"discount_total"`
 }
 
 type PokeProduct struct {
 	ID       int64  `json:"id"`
 	PokeName string `json:"poke_name"`
 	Status   string `json:"status"`
-	// must be positive
+	// must be positive, in minor units of the currency, cents for USD
 	PokePrice int64 `json:"poke_price"`
 	// must be positive
 	PokeStock int64     `json:"poke_stock"`
 	CreatedAt time.Time `json:"created_at"`
+	// held by active reservations, not available to orders
+	ReservedStock int64 `json:"reserved_stock"`
+	// available plus reserved stock
+	OnHandStock int64 `json:"on_hand_stock"`
+	// ISO 4217 code of the price
+	Currency string `json:"currency"`
+	// type of the pokemon, promotions may be scoped to it
+	PokeType string `json:"poke_type"`
+}
+
+type Promotion struct {
+	ID          int64  `json:"id"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	// percentage or fixed_amount
+	DiscountType string `json:"discount_type"`
+	// percent off every line in scope, or minor units of the currency off the lines in scope
+	DiscountValue int64 `json:"discount_value"`
+	// ISO 4217 code of a fixed amount
+	Currency sql.NullString `json:"currency"`
+	// only this product is discounted when set
+	ProductID sql.NullInt64 `json:"product_id"`
+	// only pokemon of this type are discounted when set
+	PokeType sql.NullString `json:"poke_type"`
+	// only users of this role may redeem it when set
+	UserRole sql.NullString `json:"user_role"`
+	StartsAt time.Time      `json:"starts_at"`
+	// the promotion never ends when null
+	EndsAt sql.NullTime `json:"ends_at"`
+	// redemptions of every user together, unlimited when null
+	MaxRedemptions sql.NullInt32 `json:"max_redemptions"`
+	// redemptions of a single user, unlimited when null
+	MaxRedemptionsPerUser sql.NullInt32 `json:"max_redemptions_per_user"`
+	RedemptionCount       int32         `json:"redemption_count"`
+	// username of the account which made the promotion
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PromotionRedemption struct {
+	ID          int64     `json:"id"`
+	PromotionID int64     `json:"promotion_id"`
+	UserID      int64     `json:"user_id"`
+	OrderID     int64     `json:"order_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RevokedToken struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type StockReservation struct {
+	ID        int64 `json:"id"`
+	UserID    int64 `json:"user_id"`
+	ProductID int64 `json:"product_id"`
+	Quantity  int32 `json:"quantity"`
+	// active, converted or released
+	Status string `json:"status"`
+	// the order the reservation was converted into
+	OrderID   sql.NullInt64 `json:"order_id"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
 }
 
 type User struct {
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrderItem(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	order := mockOrderData(t, user, pokemon)
+
+	arg := CreateOrderItemParams{
+		OrderID:     order.ID,
+		ProductID:   pokemon.ID,
+		ProductName: pokemon.PokeName,
+		Quantity:    order.Quantity,
+		UnitPrice:   pokemon.PokePrice,
+		Currency:    pokemon.Currency,
+		TotalPrice:  order.TotalPrice,
+	}
+
+	item, err := testQueries.CreateOrderItem(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, item)
+
+	require.Equal(t, arg.OrderID, item.OrderID)
+	require.Equal(t, arg.ProductID, item.ProductID)
+	require.Equal(t, arg.Quantity, item.Quantity)
+	require.Equal(t, arg.UnitPrice, item.UnitPrice)
+	require.Equal(t, arg.ProductName, item.ProductName)
+	require.Equal(t, arg.Currency, item.Currency)
+	require.Equal(t, arg.TotalPrice, item.TotalPrice)
+	require.NotZero(t, item.ID)
+	require.NotZero(t, item.CreatedAt)
+}
+
+func TestListOrderItems(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	order := mockOrderData(t, user, mockRandomData(t))
+
+	for i := 0; i < 3; i++ {
+		pokemon := mockRandomData(t)
+		_, err := testQueries.CreateOrderItem(context.Background(), CreateOrderItemParams{
+			OrderID:     order.ID,
+			ProductID:   pokemon.ID,
+			ProductName: pokemon.PokeName,
+			Quantity:    1,
+			UnitPrice:   pokemon.PokePrice,
+			Currency:    pokemon.Currency,
+			TotalPrice:  pokemon.PokePrice,
+		})
+		require.NoError(t, err)
+	}
+
+	items, err := testQueries.ListOrderItems(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	for _, item := range items {
+		require.Equal(t, order.ID, item.OrderID)
+	}
+}
@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSortField is returned when listing orders sorted by a field that is not in orderSortColumns
+var ErrInvalidSortField = errors.New("invalid order sort field")
+
+// orderSortColumns whitelists the sort fields of the order listings, id and an empty field only sort by id.
+// Every column is indexed along with id, so the plain ORDER BY built from it walks an index
+var orderSortColumns = map[string]string{
+	"":            "",
+	"id":          "",
+	"created_at":  "poke_orders.created_at",
+	"total_price": "poke_orders.total_price",
+	"quantity":    "poke_orders.quantity",
+}
+
+// orderListingFilter holds the filters shared by the order listings, every filter is skipped when null
+const orderListingFilter = `WHERE ($1::bigint IS NULL OR poke_orders.user_id = $1)
+    AND ($2::bigint IS NULL OR EXISTS (
+        SELECT 1 FROM order_items
+        WHERE order_items.order_id = poke_orders.id AND order_items.product_id = $2))
+    AND ($3::varchar IS NULL OR poke_orders.status = $3)
+    AND ($4::timestamptz IS NULL OR poke_orders.created_at >= $4)
+    AND ($5::timestamptz IS NULL OR poke_orders.created_at < $5)
+    AND ($6::bigint IS NULL OR poke_orders.total_price >= $6)
+    AND ($7::bigint IS NULL OR poke_orders.total_price <= $7)`
+
+const listPokemonOrderData = `SELECT id, user_id, product_id, quantity, total_price, created_at, status, paid_at, fulfilled_at, cancelled_at, refunded_at, updated_at, refunded_total, net_total, currency, discount_total FROM poke_orders
+` + orderListingFilter
+
+const listOrderDetailedData = `select poke_orders.id, users.user_name,
+    COALESCE((select string_agg(order_items.product_name, ', ' order by order_items.id)
+        FROM order_items
+        WHERE order_items.order_id = poke_orders.id), '')::varchar AS poke_name,
+    poke_orders.quantity , poke_orders.total_price , poke_orders.currency , poke_orders.status , poke_orders.created_at
+FROM (poke_orders
+inner join users on poke_orders.user_id  = users.id)
+` + orderListingFilter
+
+type ListPokemonOrderDataParams struct {
+	UserID         sql.NullInt64  `json:"user_id"`
+	ProductID      sql.NullInt64  `json:"product_id"`
+	Status         sql.NullString `json:"status"`
+	CreatedFrom    sql.NullTime   `json:"created_from"`
+	CreatedTo      sql.NullTime   `json:"created_to"`
+	MinTotal       sql.NullInt64  `json:"min_total"`
+	MaxTotal       sql.NullInt64  `json:"max_total"`
+	AfterID        sql.NullInt64  `json:"after_id"`
+	SortField      string         `json:"sort_field"`
+	SortDesc       bool           `json:"sort_desc"`
+	AfterCreatedAt sql.NullTime   `json:"after_created_at"`
+	AfterValue     sql.NullInt64  `json:"after_value"`
+	Limit          int32          `json:"limit"`
+	Offset         int32          `json:"offset"`
+}
+
+type ListOrderDetailedDataParams struct {
+	UserID         sql.NullInt64  `json:"user_id"`
+	ProductID      sql.NullInt64  `json:"product_id"`
+	Status         sql.NullString `json:"status"`
+	CreatedFrom    sql.NullTime   `json:"created_from"`
+	CreatedTo      sql.NullTime   `json:"created_to"`
+	MinTotal       sql.NullInt64  `json:"min_total"`
+	MaxTotal       sql.NullInt64  `json:"max_total"`
+	AfterID        sql.NullInt64  `json:"after_id"`
+	SortField      string         `json:"sort_field"`
+	SortDesc       bool           `json:"sort_desc"`
+	AfterCreatedAt sql.NullTime   `json:"after_created_at"`
+	AfterValue     sql.NullInt64  `json:"after_value"`
+	Limit          int32          `json:"limit"`
+	Offset         int32          `json:"offset"`
+}
+
+type ListOrderDetailedDataRow struct {
+	ID         int64     `json:"id"`
+	UserName   string    `json:"user_name"`
+	PokeName   string    `json:"poke_name"`
+	Quantity   int32     `json:"quantity"`
+	TotalPrice int64     `json:"total_price"`
+	Currency   string    `json:"currency"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// orderListingOrder appends the cursor, the ORDER BY and the page of an order listing to its filters.
+// Only the rows past the after_id cursor row are listed, after_created_at or after_value hold its sort field
+func orderListingOrder(arg ListPokemonOrderDataParams) (string, []interface{}, error) {
+	column, ok := orderSortColumns[arg.SortField]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrInvalidSortField, arg.SortField)
+	}
+
+	direction, compare := "ASC", ">"
+	if arg.SortDesc {
+		direction, compare = "DESC", "<"
+	}
+
+	args := []interface{}{
+		arg.UserID,
+		arg.ProductID,
+		arg.Status,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinTotal,
+		arg.MaxTotal,
+	}
+
+	var clause strings.Builder
+	if arg.AfterID.Valid {
+		args = append(args, arg.AfterID)
+		if column == "" {
+			fmt.Fprintf(&clause, "\n    AND poke_orders.id %s $%d", compare, len(args))
+		} else {
+			var after interface{} = arg.AfterValue
+			if arg.SortField == "created_at" {
+				after = arg.AfterCreatedAt
+			}
+			args = append(args, after)
+			fmt.Fprintf(&clause, "\n    AND (%s, poke_orders.id) %s ($%d, $%d)", column, compare, len(args), len(args)-1)
+		}
+	}
+
+	clause.WriteString("\nORDER BY ")
+	if column != "" {
+		fmt.Fprintf(&clause, "%s %s, ", column, direction)
+	}
+	fmt.Fprintf(&clause, "poke_orders.id %s", direction)
+
+	args = append(args, arg.Limit, arg.Offset)
+	fmt.Fprintf(&clause, "\nLIMIT $%d\nOFFSET $%d\n", len(args)-1, len(args))
+
+	return clause.String(), args, nil
+}
+
+// ListPokemonOrderData lists the orders matching the filters, sorted by the field of the params
+func (q *Queries) ListPokemonOrderData(ctx context.Context, arg ListPokemonOrderDataParams) ([]PokeOrder, error) {
+	order, args, err := orderListingOrder(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.QueryContext(ctx, listPokemonOrderData+order, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PokeOrder{}
+	for rows.Next() {
+		var i PokeOrder
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.TotalPrice,
+			&i.CreatedAt,
+			&i.Status,
+			&i.PaidAt,
+			&i.FulfilledAt,
+			&i.CancelledAt,
+			&i.RefundedAt,
+			&i.UpdatedAt,
+			&i.RefundedTotal,
+			&i.NetTotal,
+			&i.Currency,
+			&i.DiscountTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListOrderDetailedData lists the orders matching the filters with the name of their user and products,
+// sorted the same way as ListPokemonOrderData
+func (q *Queries) ListOrderDetailedData(ctx context.Context, arg ListOrderDetailedDataParams) ([]ListOrderDetailedDataRow, error) {
+	order, args, err := orderListingOrder(ListPokemonOrderDataParams(arg))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.QueryContext(ctx, listOrderDetailedData+order, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrderDetailedDataRow{}
+	for rows.Next() {
+		var i ListOrderDetailedDataRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserName,
+			&i.PokeName,
+			&i.Quantity,
+			&i.TotalPrice,
+			&i.Currency,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderListingOrder(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	order, args, err := orderListingOrder(ListPokemonOrderDataParams{Limit: 5, Offset: 10})
+	require.NoError(t, err)
+	require.Equal(t, "\nORDER BY poke_orders.id ASC\nLIMIT $8\nOFFSET $9\n", order)
+	require.Len(t, args, 9)
+	require.Equal(t, int32(5), args[7])
+	require.Equal(t, int32(10), args[8])
+
+	order, args, err = orderListingOrder(ListPokemonOrderDataParams{
+		AfterID:        sql.NullInt64{Int64: 7, Valid: true},
+		SortField:      "created_at",
+		SortDesc:       true,
+		AfterCreatedAt: sql.NullTime{Time: createdAt, Valid: true},
+		Limit:          5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "\n    AND (poke_orders.created_at, poke_orders.id) < ($9, $8)"+
+		"\nORDER BY poke_orders.created_at DESC, poke_orders.id DESC\nLIMIT $10\nOFFSET $11\n", order)
+	require.Equal(t, sql.NullInt64{Int64: 7, Valid: true}, args[7])
+	require.Equal(t, sql.NullTime{Time: createdAt, Valid: true}, args[8])
+
+	order, args, err = orderListingOrder(ListPokemonOrderDataParams{
+		AfterID:    sql.NullInt64{Int64: 7, Valid: true},
+		SortField:  "total_price",
+		AfterValue: sql.NullInt64{Int64: 300, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "\n    AND (poke_orders.total_price, poke_orders.id) > ($9, $8)"+
+		"\nORDER BY poke_orders.total_price ASC, poke_orders.id ASC\nLIMIT $10\nOFFSET $11\n", order)
+	require.Equal(t, sql.NullInt64{Int64: 300, Valid: true}, args[8])
+
+	_, _, err = orderListingOrder(ListPokemonOrderDataParams{SortField: "user_id; DROP TABLE poke_orders"})
+	require.True(t, errors.Is(err, ErrInvalidSortField))
+}
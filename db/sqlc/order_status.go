@@ -0,0 +1,58 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Order statuses, an order starts pending and moves along orderTransitions
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusFulfilled = "fulfilled"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// ErrInvalidOrderTransition is returned when an order cannot move from its status to the requested one
+var ErrInvalidOrderTransition = errors.New("invalid order status transition")
+
+// orderTransitions lists the statuses reachable from each status, cancelled and refunded are final
+var orderTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusFulfilled, OrderStatusRefunded},
+	OrderStatusFulfilled: {OrderStatusRefunded},
+}
+
+// CanTransitionOrder reports whether an order is allowed to move from one status to another
+func CanTransitionOrder(from string, to string) bool {
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOrderTransition wraps ErrInvalidOrderTransition with the statuses involved
+func validateOrderTransition(from string, to string) error {
+	if !CanTransitionOrder(from, to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidOrderTransition, from, to)
+	}
+	return nil
+}
+
+// closingStatus is the status an order ends in once all its lines are refunded,
+// a pending order was never paid so it is cancelled instead
+func closingStatus(from string) string {
+	if from == OrderStatusPending {
+		return OrderStatusCancelled
+	}
+	return OrderStatusRefunded
+}
+
+// restocksOrder reports whether the transition gives the ordered quantity back to the product,
+// which is the case as long as the pokemon were not handed over yet
+func restocksOrder(from string, to string) bool {
+	return from != OrderStatusFulfilled && (to == OrderStatusCancelled || to == OrderStatusRefunded)
+}
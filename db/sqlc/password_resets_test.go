@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func mockCreatePasswordReset(t *testing.T, account Account, expiresAt time.Time) (PasswordReset, string) {
+	resetToken, err := util.RandomSecret(32)
+	require.NoError(t, err)
+
+	arg := CreatePasswordResetParams{
+		HashedToken: util.HashSecret(resetToken),
+		Username:    account.Username,
+		ExpiresAt:   expiresAt,
+	}
+
+	reset, err := testQueries.CreatePasswordReset(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, reset)
+
+	require.Equal(t, arg.HashedToken, reset.HashedToken)
+	require.Equal(t, arg.Username, reset.Username)
+	require.WithinDuration(t, arg.ExpiresAt, reset.ExpiresAt, time.Second)
+	require.False(t, reset.UsedAt.Valid)
+	require.NotZero(t, reset.CreatedAt)
+
+	return reset, resetToken
+}
+
+func TestCreatePasswordReset(t *testing.T) {
+	mockCreatePasswordReset(t, mockCreateAccountLog(t), time.Now().Add(time.Hour))
+}
+
+func TestUsePasswordReset(t *testing.T) {
+	account := mockCreateAccountLog(t)
+	reset1, _ := mockCreatePasswordReset(t, account, time.Now().Add(time.Hour))
+
+	reset2, err := testQueries.UsePasswordReset(context.Background(), reset1.HashedToken)
+	require.NoError(t, err)
+	require.Equal(t, reset1.HashedToken, reset2.HashedToken)
+	require.True(t, reset2.UsedAt.Valid)
+
+	_, err = testQueries.UsePasswordReset(context.Background(), reset1.HashedToken)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	expired, _ := mockCreatePasswordReset(t, account, time.Now().Add(-time.Minute))
+	_, err = testQueries.UsePasswordReset(context.Background(), expired.HashedToken)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestExpirePasswordResets(t *testing.T) {
+	account := mockCreateAccountLog(t)
+	reset, _ := mockCreatePasswordReset(t, account, time.Now().Add(time.Hour))
+
+	err := testQueries.ExpirePasswordResets(context.Background(), account.Username)
+	require.NoError(t, err)
+
+	_, err = testQueries.UsePasswordReset(context.Background(), reset.HashedToken)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
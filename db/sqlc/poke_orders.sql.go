@@ -5,8 +5,46 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const addPokemonOrderRefundedTotal = `-- name: AddPokemonOrderRefundedTotal :one
+UPDATE poke_orders
+SET refunded_total = refunded_total + $1,
+    updated_at = now()
+WHERE id = $2
+RETURNING id, user_id, product_id, quantity, total_price, created_at, status, paid_at, fulfilled_at, cancelled_at, refunded_at, updated_at, refunded_total, net_total, currency, discount_total
+`
+
+type AddPokemonOrderRefundedTotalParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddPokemonOrderRefundedTotal(ctx context.Context, arg AddPokemonOrderRefundedTotalParams) (PokeOrder, error) {
+	row := q.db.QueryRowContext(ctx, addPokemonOrderRefundedTotal, arg.Amount, arg.ID)
+	var i PokeOrder
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.Status,
+		&i.PaidAt,
+		&i.FulfilledAt,
+		&i.CancelledAt,
+		&i.RefundedAt,
+		&i.UpdatedAt,
+		&i.RefundedTotal,
+		&i.NetTotal,
+		&i.Currency,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
 const cancelPokemonOrderData = `-- name: CancelPokemonOrderData :exec
 DELETE FROM poke_orders
 WHERE id = $1
@@ -18,7 +56,7 @@ func (q *Queries) CancelPokemonOrderData(ctx context.Context, id int64) error {
 }
 
 const getPokemonOrderData = `-- name: GetPokemonOrderData :one
-SELECT id, user_id, product_id, quantity, total_price, order_detail, created_at FROM poke_orders
+SELECT id, user_id, product_id, quantity, total_price, created_at, status, paid_at, fulfilled_at, cancelled_at, refunded_at, updated_at, refunded_total, net_total, currency, discount_total FROM poke_orders
 WHERE id = $1 LIMIT 1
 `
 
@@ -31,26 +69,66 @@ func (q *Queries) GetPokemonOrderData(ctx context.Context, id int64) (PokeOrder,
 		&i.ProductID,
 		&i.Quantity,
 		&i.TotalPrice,
-		&i.OrderDetail,
 		&i.CreatedAt,
+		&i.Status,
+		&i.PaidAt,
+		&i.FulfilledAt,
+		&i.CancelledAt,
+		&i.RefundedAt,
+		&i.UpdatedAt,
+		&i.RefundedTotal,
+		&i.NetTotal,
+		&i.Currency,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
+const getPokemonOrderDataForUpdate = `-- name: GetPokemonOrderDataForUpdate :one
+SELECT id, user_id, product_id, quantity, total_price, created_at, status, paid_at, fulfilled_at, cancelled_at, refunded_at, updated_at, refunded_total, net_total, currency, discount_total FROM poke_orders
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetPokemonOrderDataForUpdate(ctx context.Context, id int64) (PokeOrder, error) {
+	row := q.db.QueryRowContext(ctx, getPokemonOrderDataForUpdate, id)
+	var i PokeOrder
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.Status,
+		&i.PaidAt,
+		&i.FulfilledAt,
+		&i.CancelledAt,
+		&i.RefundedAt,
+		&i.UpdatedAt,
+		&i.RefundedTotal,
+		&i.NetTotal,
+		&i.Currency,
+		&i.DiscountTotal,
 	)
 	return i, err
 }
 
 const insertPokemonOrderData = `-- name: InsertPokemonOrderData :one
 INSERT INTO poke_orders (
-    user_id, product_id,quantity,total_price,order_detail
+    user_id, product_id,quantity,total_price,currency,discount_total
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, user_id, product_id, quantity, total_price, order_detail, created_at
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, user_id, product_id, quantity, total_price, created_at, status, paid_at, fulfilled_at, cancelled_at, refunded_at, updated_at, refunded_total, net_total, currency, discount_total
 `
 
 type InsertPokemonOrderDataParams struct {
-	UserID      int64  `json:"user_id"`
-	ProductID   int64  `json:"product_id"`
-	Quantity    int32  `json:"quantity"`
-	TotalPrice  int64  `json:"total_price"`
-	OrderDetail string `json:"order_detail"`
+	UserID        int64         `json:"user_id"`
+	ProductID     sql.NullInt64 `json:"product_id"`
+	Quantity      int32         `json:"quantity"`
+	TotalPrice    int64         `json:"total_price"`
+	Currency      string        `json:"currency"`
+	DiscountTotal int64         `json:"discount_total"`
 }
 
 func (q *Queries) InsertPokemonOrderData(ctx context.Context, arg InsertPokemonOrderDataParams) (PokeOrder, error) {
@@ -59,7 +137,8 @@ func (q *Queries) InsertPokemonOrderData(ctx context.Context, arg InsertPokemonO
 		arg.ProductID,
 		arg.Quantity,
 		arg.TotalPrice,
-		arg.OrderDetail,
+		arg.Currency,
+		arg.DiscountTotal,
 	)
 	var i PokeOrder
 	err := row.Scan(
@@ -68,108 +147,59 @@ func (q *Queries) InsertPokemonOrderData(ctx context.Context, arg InsertPokemonO
 		&i.ProductID,
 		&i.Quantity,
 		&i.TotalPrice,
-		&i.OrderDetail,
 		&i.CreatedAt,
+		&i.Status,
+		&i.PaidAt,
+		&i.FulfilledAt,
+		&i.CancelledAt,
+		&i.RefundedAt,
+		&i.UpdatedAt,
+		&i.RefundedTotal,
+		&i.NetTotal,
+		&i.Currency,
+		&i.DiscountTotal,
 	)
 	return i, err
 }
 
-const listOrderDetailedData = `-- name: ListOrderDetailedData :many
-select poke_orders.id, users.user_name, poke_products.poke_name, poke_orders.quantity , poke_orders.total_price , poke_orders.order_detail 
-FROM ((poke_orders
-inner join users on poke_orders.user_id  = users.id)
-inner join poke_products on poke_orders.product_id = poke_products.id)
-order by id
-limit $1
-OFFSET $2
-`
-
-type ListOrderDetailedDataParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
-}
-
-type ListOrderDetailedDataRow struct {
-	ID          int64  `json:"id"`
-	UserName    string `json:"user_name"`
-	PokeName    string `json:"poke_name"`
-	Quantity    int32  `json:"quantity"`
-	TotalPrice  int64  `json:"total_price"`
-	OrderDetail string `json:"order_detail"`
-}
-
-func (q *Queries) ListOrderDetailedData(ctx context.Context, arg ListOrderDetailedDataParams) ([]ListOrderDetailedDataRow, error) {
-	rows, err := q.db.QueryContext(ctx, listOrderDetailedData, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []ListOrderDetailedDataRow{}
-	for rows.Next() {
-		var i ListOrderDetailedDataRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserName,
-			&i.PokeName,
-			&i.Quantity,
-			&i.TotalPrice,
-			&i.OrderDetail,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const listPokemonOrderData = `-- name: ListPokemonOrderData :many
-SELECT id, user_id, product_id, quantity, total_price, order_detail, created_at FROM poke_orders
-ORDER BY id
-LIMIT $1
-OFFSET $2
+const updatePokemonOrderStatus = `-- name: UpdatePokemonOrderStatus :one
+UPDATE poke_orders
+SET status = $2,
+    paid_at = CASE WHEN $2 = 'paid' THEN now() ELSE paid_at END,
+    fulfilled_at = CASE WHEN $2 = 'fulfilled' THEN now() ELSE fulfilled_at END,
+    cancelled_at = CASE WHEN $2 = 'cancelled' THEN now() ELSE cancelled_at END,
+    refunded_at = CASE WHEN $2 = 'refunded' THEN now() ELSE refunded_at END,
+    refunded_total = CASE WHEN $2 IN ('cancelled', 'refunded') THEN total_price ELSE refunded_total END,
+    updated_at = now()
+WHERE id = $1
+RETURNING id, user_id, product_id, quantity, total_price, created_at, status, paid_at, fulfilled_at, cancelled_at, refunded_at, updated_at, refunded_total, net_total, currency, discount_total
 `
 
-type ListPokemonOrderDataParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+type UpdatePokemonOrderStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
 }
 
-// WHERE
-//     user_id = $1 OR
-//     product_id = $2
-func (q *Queries) ListPokemonOrderData(ctx context.Context, arg ListPokemonOrderDataParams) ([]PokeOrder, error) {
-	rows, err := q.db.QueryContext(ctx, listPokemonOrderData, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []PokeOrder{}
-	for rows.Next() {
-		var i PokeOrder
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.ProductID,
-			&i.Quantity,
-			&i.TotalPrice,
-			&i.OrderDetail,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) UpdatePokemonOrderStatus(ctx context.Context, arg UpdatePokemonOrderStatusParams) (PokeOrder, error) {
+	row := q.db.QueryRowContext(ctx, updatePokemonOrderStatus, arg.ID, arg.Status)
+	var i PokeOrder
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.Status,
+		&i.PaidAt,
+		&i.FulfilledAt,
+		&i.CancelledAt,
+		&i.RefundedAt,
+		&i.UpdatedAt,
+		&i.RefundedTotal,
+		&i.NetTotal,
+		&i.Currency,
+		&i.DiscountTotal,
+	)
+	return i, err
 }
@@ -2,19 +2,20 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
-	"github.com/gunhachi/poke-blackmarket/util"
 	"github.com/stretchr/testify/require"
 )
 
 func mockOrderData(t *testing.T, user User, pokemon PokeProduct) PokeOrder {
 	arg := InsertPokemonOrderDataParams{
-		UserID:      user.ID,
-		ProductID:   pokemon.ID,
-		Quantity:    2,
-		TotalPrice:  2 * pokemon.PokePrice,
-		OrderDetail: util.RandomRole(),
+		UserID:     user.ID,
+		ProductID:  sql.NullInt64{Int64: pokemon.ID, Valid: true},
+		Quantity:   2,
+		TotalPrice: 2 * pokemon.PokePrice,
+		Currency:   pokemon.Currency,
 	}
 
 	data, err := testQueries.InsertPokemonOrderData(context.Background(), arg)
@@ -25,7 +26,9 @@ func mockOrderData(t *testing.T, user User, pokemon PokeProduct) PokeOrder {
 	require.Equal(t, arg.ProductID, data.ProductID)
 	require.Equal(t, arg.Quantity, data.Quantity)
 	require.Equal(t, arg.TotalPrice, data.TotalPrice)
-	require.Equal(t, arg.OrderDetail, data.OrderDetail)
+	require.Equal(t, arg.Currency, data.Currency)
+	require.Equal(t, OrderStatusPending, data.Status)
+	require.False(t, data.PaidAt.Valid)
 
 	require.NotZero(t, data.ID)
 	require.NotZero(t, data.CreatedAt)
@@ -48,7 +51,7 @@ func TestGetPokemonOrderData(t *testing.T) {
 	require.NotEmpty(t, data)
 
 	require.Equal(t, user.ID, data.UserID)
-	require.Equal(t, poke.ID, data.ProductID)
+	require.Equal(t, poke.ID, data.ProductID.Int64)
 }
 
 func TestListPokemonOrderData(t *testing.T) {
@@ -82,3 +85,117 @@ func TestCancelPokemonOrderData(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestUpdatePokemonOrderStatus(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	poke := mockRandomData(t)
+	order := mockOrderData(t, user, poke)
+
+	paid, err := testQueries.UpdatePokemonOrderStatus(context.Background(), UpdatePokemonOrderStatusParams{
+		ID:     order.ID,
+		Status: OrderStatusPaid,
+	})
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusPaid, paid.Status)
+	require.True(t, paid.PaidAt.Valid)
+	require.False(t, paid.FulfilledAt.Valid)
+
+	fulfilled, err := testQueries.UpdatePokemonOrderStatus(context.Background(), UpdatePokemonOrderStatusParams{
+		ID:     order.ID,
+		Status: OrderStatusFulfilled,
+	})
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusFulfilled, fulfilled.Status)
+	require.Equal(t, paid.PaidAt, fulfilled.PaidAt)
+	require.True(t, fulfilled.FulfilledAt.Valid)
+}
+
+func TestListPokemonOrderDataFilter(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	pokemon1 := mockRandomData(t)
+	pokemon2 := mockRandomData(t)
+
+	mockOrderTx(t, user, pokemon1)
+	mockOrderTx(t, user, pokemon1)
+	paid := mockOrderTx(t, user, pokemon2)
+	mockOrderTx(t, mockCreateUserAccount(t), pokemon1)
+
+	_, err := testQueries.UpdatePokemonOrderStatus(context.Background(), UpdatePokemonOrderStatusParams{
+		ID:     paid.Order.ID,
+		Status: OrderStatusPaid,
+	})
+	require.NoError(t, err)
+
+	arg := ListPokemonOrderDataParams{
+		UserID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		SortField: "total_price",
+		SortDesc:  true,
+		Limit:     10,
+	}
+	orders, err := testQueries.ListPokemonOrderData(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, orders, 3)
+	for i, order := range orders {
+		require.Equal(t, user.ID, order.UserID)
+		if i > 0 {
+			require.LessOrEqual(t, order.TotalPrice, orders[i-1].TotalPrice)
+		}
+	}
+
+	arg.ProductID = sql.NullInt64{Int64: pokemon1.ID, Valid: true}
+	orders, err = testQueries.ListPokemonOrderData(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+
+	arg.ProductID = sql.NullInt64{}
+	arg.Status = sql.NullString{String: OrderStatusPaid, Valid: true}
+	orders, err = testQueries.ListPokemonOrderData(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	require.Equal(t, paid.Order.ID, orders[0].ID)
+
+	arg.Status = sql.NullString{}
+	arg.CreatedFrom = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	orders, err = testQueries.ListPokemonOrderData(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, orders)
+
+	detailed, err := testQueries.ListOrderDetailedData(context.Background(), ListOrderDetailedDataParams{
+		UserID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		ProductID: sql.NullInt64{Int64: pokemon2.ID, Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, detailed, 1)
+	require.Equal(t, pokemon2.PokeName, detailed[0].PokeName)
+}
+
+func TestListPokemonOrderDataAfterID(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+
+	for i := 0; i < 3; i++ {
+		mockOrderTx(t, user, pokemon)
+	}
+
+	arg := ListPokemonOrderDataParams{
+		UserID:    sql.NullInt64{Int64: user.ID, Valid: true},
+		SortField: "total_price",
+		SortDesc:  true,
+		Limit:     2,
+	}
+	page1, err := testQueries.ListPokemonOrderData(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page1, 2)
+
+	last := page1[len(page1)-1]
+	arg.AfterID = sql.NullInt64{Int64: last.ID, Valid: true}
+	arg.AfterValue = sql.NullInt64{Int64: last.TotalPrice, Valid: true}
+	page2, err := testQueries.ListPokemonOrderData(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page2, 1)
+
+	require.NotEqual(t, page1[0].ID, page2[0].ID)
+	require.NotEqual(t, page1[1].ID, page2[0].ID)
+	require.LessOrEqual(t, page2[0].TotalPrice, last.TotalPrice)
+}
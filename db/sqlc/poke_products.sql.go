@@ -5,13 +5,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const addPokemonStockData = `-- name: AddPokemonStockData :one
 UPDATE poke_products
 SET poke_stock = poke_stock + $1
 WHERE id = $2
-RETURNING id, poke_name, status, poke_price, poke_stock, created_at
+RETURNING id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type
 `
 
 type AddPokemonStockDataParams struct {
@@ -29,16 +30,50 @@ func (q *Queries) AddPokemonStockData(ctx context.Context, arg AddPokemonStockDa
 		&i.PokePrice,
 		&i.PokeStock,
 		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
+	)
+	return i, err
+}
+
+const claimReservedStockData = `-- name: ClaimReservedStockData :one
+UPDATE poke_products
+SET reserved_stock = reserved_stock - $1
+WHERE id = $2
+RETURNING id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type
+`
+
+type ClaimReservedStockDataParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) ClaimReservedStockData(ctx context.Context, arg ClaimReservedStockDataParams) (PokeProduct, error) {
+	row := q.db.QueryRowContext(ctx, claimReservedStockData, arg.Amount, arg.ID)
+	var i PokeProduct
+	err := row.Scan(
+		&i.ID,
+		&i.PokeName,
+		&i.Status,
+		&i.PokePrice,
+		&i.PokeStock,
+		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
 	)
 	return i, err
 }
 
 const createPokemonData = `-- name: CreatePokemonData :one
 INSERT INTO poke_products (
-    poke_name,status,poke_price,poke_stock
+    poke_name,status,poke_price,poke_stock,currency,poke_type
 ) VALUES (
-	$1, $2, $3, $4
-) RETURNING id, poke_name, status, poke_price, poke_stock, created_at
+	$1, $2, $3, $4, $5, $6
+) RETURNING id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type
 `
 
 type CreatePokemonDataParams struct {
@@ -46,6 +81,8 @@ type CreatePokemonDataParams struct {
 	Status    string `json:"status"`
 	PokePrice int64  `json:"poke_price"`
 	PokeStock int64  `json:"poke_stock"`
+	Currency  string `json:"currency"`
+	PokeType  string `json:"poke_type"`
 }
 
 func (q *Queries) CreatePokemonData(ctx context.Context, arg CreatePokemonDataParams) (PokeProduct, error) {
@@ -54,6 +91,8 @@ func (q *Queries) CreatePokemonData(ctx context.Context, arg CreatePokemonDataPa
 		arg.Status,
 		arg.PokePrice,
 		arg.PokeStock,
+		arg.Currency,
+		arg.PokeType,
 	)
 	var i PokeProduct
 	err := row.Scan(
@@ -63,6 +102,10 @@ func (q *Queries) CreatePokemonData(ctx context.Context, arg CreatePokemonDataPa
 		&i.PokePrice,
 		&i.PokeStock,
 		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
 	)
 	return i, err
 }
@@ -70,8 +113,8 @@ func (q *Queries) CreatePokemonData(ctx context.Context, arg CreatePokemonDataPa
 const deductPokemonStockData = `-- name: DeductPokemonStockData :one
 UPDATE poke_products
 SET poke_stock = poke_stock - $1
-WHERE id = $2
-RETURNING id, poke_name, status, poke_price, poke_stock, created_at
+WHERE id = $2 AND poke_stock >= $1
+RETURNING id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type
 `
 
 type DeductPokemonStockDataParams struct {
@@ -89,12 +132,16 @@ func (q *Queries) DeductPokemonStockData(ctx context.Context, arg DeductPokemonS
 		&i.PokePrice,
 		&i.PokeStock,
 		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
 	)
 	return i, err
 }
 
 const getPokemonData = `-- name: GetPokemonData :one
-SELECT id, poke_name, status, poke_price, poke_stock, created_at FROM poke_products
+SELECT id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type FROM poke_products
 WHERE id = $1 LIMIT 1
 `
 
@@ -108,24 +155,55 @@ func (q *Queries) GetPokemonData(ctx context.Context, id int64) (PokeProduct, er
 		&i.PokePrice,
 		&i.PokeStock,
 		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
+	)
+	return i, err
+}
+
+const getPokemonDataForUpdate = `-- name: GetPokemonDataForUpdate :one
+SELECT id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type FROM poke_products
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetPokemonDataForUpdate(ctx context.Context, id int64) (PokeProduct, error) {
+	row := q.db.QueryRowContext(ctx, getPokemonDataForUpdate, id)
+	var i PokeProduct
+	err := row.Scan(
+		&i.ID,
+		&i.PokeName,
+		&i.Status,
+		&i.PokePrice,
+		&i.PokeStock,
+		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
 	)
 	return i, err
 }
 
 const listPokemonData = `-- name: ListPokemonData :many
-SELECT id, poke_name, status, poke_price, poke_stock, created_at FROM poke_products
+SELECT id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type FROM poke_products
+WHERE $1::bigint IS NULL OR id > $1
 ORDER BY id
-LIMIT $1
-OFFSET $2
+LIMIT $2
+OFFSET $3
 `
 
 type ListPokemonDataParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	AfterID sql.NullInt64 `json:"after_id"`
+	Limit   int32         `json:"limit"`
+	Offset  int32         `json:"offset"`
 }
 
+// a null after_id starts from the first pokemon
 func (q *Queries) ListPokemonData(ctx context.Context, arg ListPokemonDataParams) ([]PokeProduct, error) {
-	rows, err := q.db.QueryContext(ctx, listPokemonData, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listPokemonData, arg.AfterID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -140,6 +218,10 @@ func (q *Queries) ListPokemonData(ctx context.Context, arg ListPokemonDataParams
 			&i.PokePrice,
 			&i.PokeStock,
 			&i.CreatedAt,
+			&i.ReservedStock,
+			&i.OnHandStock,
+			&i.Currency,
+			&i.PokeType,
 		); err != nil {
 			return nil, err
 		}
@@ -154,26 +236,92 @@ func (q *Queries) ListPokemonData(ctx context.Context, arg ListPokemonDataParams
 	return items, nil
 }
 
+const releasePokemonStockData = `-- name: ReleasePokemonStockData :one
+UPDATE poke_products
+SET poke_stock = poke_stock + $1,
+    reserved_stock = reserved_stock - $1
+WHERE id = $2
+RETURNING id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type
+`
+
+type ReleasePokemonStockDataParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) ReleasePokemonStockData(ctx context.Context, arg ReleasePokemonStockDataParams) (PokeProduct, error) {
+	row := q.db.QueryRowContext(ctx, releasePokemonStockData, arg.Amount, arg.ID)
+	var i PokeProduct
+	err := row.Scan(
+		&i.ID,
+		&i.PokeName,
+		&i.Status,
+		&i.PokePrice,
+		&i.PokeStock,
+		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
+	)
+	return i, err
+}
+
+const reservePokemonStockData = `-- name: ReservePokemonStockData :one
+UPDATE poke_products
+SET poke_stock = poke_stock - $1,
+    reserved_stock = reserved_stock + $1
+WHERE id = $2 AND poke_stock >= $1
+RETURNING id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type
+`
+
+type ReservePokemonStockDataParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) ReservePokemonStockData(ctx context.Context, arg ReservePokemonStockDataParams) (PokeProduct, error) {
+	row := q.db.QueryRowContext(ctx, reservePokemonStockData, arg.Amount, arg.ID)
+	var i PokeProduct
+	err := row.Scan(
+		&i.ID,
+		&i.PokeName,
+		&i.Status,
+		&i.PokePrice,
+		&i.PokeStock,
+		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
+	)
+	return i, err
+}
+
 const updatePokemonData = `-- name: UpdatePokemonData :one
 UPDATE poke_products
-SET status = $2, poke_price = $3, poke_stock = $4
-WHERE id = $1
-RETURNING id, poke_name, status, poke_price, poke_stock, created_at
+SET status = $1, poke_price = $2, poke_stock = $3,
+    poke_type = COALESCE($4, poke_type)
+WHERE id = $5
+RETURNING id, poke_name, status, poke_price, poke_stock, created_at, reserved_stock, on_hand_stock, currency, poke_type
 `
 
 type UpdatePokemonDataParams struct {
-	ID        int64  `json:"id"`
-	Status    string `json:"status"`
-	PokePrice int64  `json:"poke_price"`
-	PokeStock int64  `json:"poke_stock"`
+	Status    string         `json:"status"`
+	PokePrice int64          `json:"poke_price"`
+	PokeStock int64          `json:"poke_stock"`
+	PokeType  sql.NullString `json:"poke_type"`
+	ID        int64          `json:"id"`
 }
 
+// a null poke_type keeps the type of the pokemon
 func (q *Queries) UpdatePokemonData(ctx context.Context, arg UpdatePokemonDataParams) (PokeProduct, error) {
 	row := q.db.QueryRowContext(ctx, updatePokemonData,
-		arg.ID,
 		arg.Status,
 		arg.PokePrice,
 		arg.PokeStock,
+		arg.PokeType,
+		arg.ID,
 	)
 	var i PokeProduct
 	err := row.Scan(
@@ -183,6 +331,10 @@ func (q *Queries) UpdatePokemonData(ctx context.Context, arg UpdatePokemonDataPa
 		&i.PokePrice,
 		&i.PokeStock,
 		&i.CreatedAt,
+		&i.ReservedStock,
+		&i.OnHandStock,
+		&i.Currency,
+		&i.PokeType,
 	)
 	return i, err
 }
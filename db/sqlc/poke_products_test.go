@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -13,8 +14,9 @@ func mockRandomData(t *testing.T) PokeProduct {
 	arg := CreatePokemonDataParams{
 		PokeName:  util.RandomUser(),
 		Status:    util.RandomUser(),
-		PokeStock: util.RandomAmount(),
+		PokeStock: util.RandomInt(10, 1000),
 		PokePrice: util.RandomAmount(),
+		Currency:  DefaultCurrency,
 	}
 
 	data, err := testQueries.CreatePokemonData(context.Background(), arg)
@@ -25,6 +27,7 @@ func mockRandomData(t *testing.T) PokeProduct {
 	require.Equal(t, arg.Status, data.Status)
 	require.Equal(t, arg.PokeStock, data.PokeStock)
 	require.Equal(t, arg.PokePrice, data.PokePrice)
+	require.Equal(t, arg.Currency, data.Currency)
 
 	require.NotZero(t, data.ID)
 	require.NotZero(t, data.CreatedAt)
@@ -74,7 +77,7 @@ func TestDeductPokemonStockData(t *testing.T) {
 	data1 := mockRandomData(t)
 
 	arg := DeductPokemonStockDataParams{
-		Amount: util.RandomAmount(),
+		Amount: util.RandomInt(0, data1.PokeStock),
 		ID:     data1.ID,
 	}
 
@@ -90,6 +93,20 @@ func TestDeductPokemonStockData(t *testing.T) {
 	require.WithinDuration(t, data1.CreatedAt, data2.CreatedAt, time.Second)
 }
 
+func TestDeductPokemonStockDataOversell(t *testing.T) {
+	data1 := mockRandomData(t)
+
+	_, err := testQueries.DeductPokemonStockData(context.Background(), DeductPokemonStockDataParams{
+		Amount: data1.PokeStock + 1,
+		ID:     data1.ID,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	data2, err := testQueries.GetPokemonData(context.Background(), data1.ID)
+	require.NoError(t, err)
+	require.Equal(t, data1.PokeStock, data2.PokeStock)
+}
+
 func TestListPokemonData(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		mockRandomData(t)
@@ -110,6 +127,25 @@ func TestListPokemonData(t *testing.T) {
 	}
 }
 
+func TestListPokemonDataAfterID(t *testing.T) {
+	first := mockRandomData(t)
+	second := mockRandomData(t)
+
+	arg := ListPokemonDataParams{
+		AfterID: sql.NullInt64{Int64: first.ID, Valid: true},
+		Limit:   5,
+	}
+
+	data, err := testQueries.ListPokemonData(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, data)
+	require.Equal(t, second.ID, data[0].ID)
+
+	for _, poke := range data {
+		require.Greater(t, poke.ID, first.ID)
+	}
+}
+
 func TestUpdatePokemonStockData(t *testing.T) {
 	data1 := mockRandomData(t)
 
@@ -129,4 +165,12 @@ func TestUpdatePokemonStockData(t *testing.T) {
 	require.Equal(t, data1.Status, data2.Status)
 	require.Equal(t, data1.PokePrice, data2.PokePrice)
 	require.WithinDuration(t, data1.CreatedAt, data2.CreatedAt, time.Second)
+
+	// a null type keeps the type of the pokemon
+	require.Equal(t, data1.PokeType, data2.PokeType)
+
+	arg.PokeType = sql.NullString{String: "poison", Valid: true}
+	data3, err := testQueries.UpdatePokemonData(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.PokeType.String, data3.PokeType)
 }
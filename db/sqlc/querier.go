@@ -4,27 +4,91 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddCartItem(ctx context.Context, arg AddCartItemParams) (CartItem, error)
+	AddPokemonOrderRefundedTotal(ctx context.Context, arg AddPokemonOrderRefundedTotalParams) (PokeOrder, error)
 	AddPokemonStockData(ctx context.Context, arg AddPokemonStockDataParams) (PokeProduct, error)
+	BlockAccountSessions(ctx context.Context, username string) error
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
 	CancelPokemonOrderData(ctx context.Context, id int64) error
+	ClaimReservedStockData(ctx context.Context, arg ClaimReservedStockDataParams) (PokeProduct, error)
+	ClearCart(ctx context.Context, userID int64) error
+	CountUserPromotionRedemptions(ctx context.Context, arg CountUserPromotionRedemptionsParams) (int64, error)
 	CreateAccountLog(ctx context.Context, arg CreateAccountLogParams) (Account, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateOrderDiscount(ctx context.Context, arg CreateOrderDiscountParams) (OrderDiscount, error)
+	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (OrderItem, error)
+	CreateOrderRefund(ctx context.Context, arg CreateOrderRefundParams) (OrderRefund, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
 	CreatePokemonData(ctx context.Context, arg CreatePokemonDataParams) (PokeProduct, error)
+	CreatePromotion(ctx context.Context, arg CreatePromotionParams) (Promotion, error)
+	CreatePromotionRedemption(ctx context.Context, arg CreatePromotionRedemptionParams) (PromotionRedemption, error)
+	CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) error
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateStockReservation(ctx context.Context, arg CreateStockReservationParams) (StockReservation, error)
 	CreateUserAccount(ctx context.Context, arg CreateUserAccountParams) (User, error)
 	DeductPokemonStockData(ctx context.Context, arg DeductPokemonStockDataParams) (PokeProduct, error)
+	DeleteCartItem(ctx context.Context, arg DeleteCartItemParams) (CartItem, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context, expiredBefore time.Time) error
+	DeleteExpiredRevokedTokens(ctx context.Context) error
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteLoginFailure(ctx context.Context, arg DeleteLoginFailureParams) error
 	DeleteUserAccount(ctx context.Context, id int64) error
+	ExpirePasswordResets(ctx context.Context, username string) error
 	GetAccountLog(ctx context.Context, username string) (Account, error)
+	GetAccountRevocation(ctx context.Context, username string) (AccountRevocation, error)
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLoginLockedUntil(ctx context.Context, arg GetLoginLockedUntilParams) (time.Time, error)
 	GetPokemonData(ctx context.Context, id int64) (PokeProduct, error)
+	GetPokemonDataForUpdate(ctx context.Context, id int64) (PokeProduct, error)
 	GetPokemonOrderData(ctx context.Context, id int64) (PokeOrder, error)
+	GetPokemonOrderDataForUpdate(ctx context.Context, id int64) (PokeOrder, error)
+	GetPromotion(ctx context.Context, id int64) (Promotion, error)
+	GetPromotionByCodeForUpdate(ctx context.Context, code string) (Promotion, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetStockReservation(ctx context.Context, id int64) (StockReservation, error)
+	GetStockReservationForUpdate(ctx context.Context, id int64) (StockReservation, error)
 	GetUserAccount(ctx context.Context, id int64) (User, error)
 	InsertPokemonOrderData(ctx context.Context, arg InsertPokemonOrderDataParams) (PokeOrder, error)
-	ListOrderDetailedData(ctx context.Context, arg ListOrderDetailedDataParams) ([]ListOrderDetailedDataRow, error)
+	IsTokenRevoked(ctx context.Context, id uuid.UUID) (bool, error)
+	ListApiKeys(ctx context.Context, arg ListApiKeysParams) ([]ApiKey, error)
+	ListCartItems(ctx context.Context, userID int64) ([]CartItem, error)
+	ListExpiredStockReservations(ctx context.Context, arg ListExpiredStockReservationsParams) ([]StockReservation, error)
+	ListOrderDiscounts(ctx context.Context, orderID int64) ([]OrderDiscount, error)
+	ListOrderItems(ctx context.Context, orderID int64) ([]OrderItem, error)
+	ListOrderRefunds(ctx context.Context, orderID int64) ([]OrderRefund, error)
 	ListPokemonData(ctx context.Context, arg ListPokemonDataParams) ([]PokeProduct, error)
-	ListPokemonOrderData(ctx context.Context, arg ListPokemonOrderDataParams) ([]PokeOrder, error)
+	ListPromotions(ctx context.Context, arg ListPromotionsParams) ([]Promotion, error)
 	ListUserAccount(ctx context.Context, arg ListUserAccountParams) ([]User, error)
+	ListUserAccountByName(ctx context.Context, arg ListUserAccountByNameParams) ([]User, error)
+	LockLoginFailure(ctx context.Context, arg LockLoginFailureParams) error
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginFailure, error)
+	RedeemPromotion(ctx context.Context, id int64) (Promotion, error)
+	RefundOrderItem(ctx context.Context, arg RefundOrderItemParams) (OrderItem, error)
+	ReleasePokemonStockData(ctx context.Context, arg ReleasePokemonStockDataParams) (PokeProduct, error)
+	ReservePokemonStockData(ctx context.Context, arg ReservePokemonStockDataParams) (PokeProduct, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (ApiKey, error)
+	SaveIdempotencyResponse(ctx context.Context, arg SaveIdempotencyResponseParams) error
+	UpdateAccountDisabled(ctx context.Context, arg UpdateAccountDisabledParams) (Account, error)
+	UpdateAccountPassword(ctx context.Context, arg UpdateAccountPasswordParams) (Account, error)
+	UpdateAccountTotp(ctx context.Context, arg UpdateAccountTotpParams) (Account, error)
+	UpdateApiKeyLastUsed(ctx context.Context, id int64) error
+	UpdateCartItem(ctx context.Context, arg UpdateCartItemParams) (CartItem, error)
 	UpdatePokemonData(ctx context.Context, arg UpdatePokemonDataParams) (PokeProduct, error)
+	UpdatePokemonOrderStatus(ctx context.Context, arg UpdatePokemonOrderStatusParams) (PokeOrder, error)
+	UpdateStockReservationStatus(ctx context.Context, arg UpdateStockReservationStatusParams) (StockReservation, error)
 	UpdateUserAccountRole(ctx context.Context, arg UpdateUserAccountRoleParams) (User, error)
+	UpsertAccountRevocation(ctx context.Context, arg UpsertAccountRevocationParams) (AccountRevocation, error)
+	UseAccountRecoveryCode(ctx context.Context, arg UseAccountRecoveryCodeParams) (Account, error)
+	UseAccountTotpStep(ctx context.Context, arg UseAccountTotpStepParams) (Account, error)
+	UsePasswordReset(ctx context.Context, hashedToken string) (PasswordReset, error)
 }
 
 var _ Querier = (*Queries)(nil)
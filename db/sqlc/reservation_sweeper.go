@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// reservationSweepBatch is how many expired reservations are released per transaction
+const reservationSweepBatch = 100
+
+// RunReservationSweeper releases the expired reservations every interval until the context is done,
+// a zero interval disables the sweeper
+func RunReservationSweeper(ctx context.Context, store Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			SweepReservations(ctx, store, time.Now())
+		}
+	}
+}
+
+// SweepReservations releases every reservation expired at the given time batch after batch,
+// it returns how many were released
+func SweepReservations(ctx context.Context, store Store, now time.Time) int {
+	released := 0
+	for {
+		result, err := store.ReleaseExpiredReservationsTx(ctx, ReleaseExpiredReservationsTxParams{
+			Now:   now,
+			Limit: reservationSweepBatch,
+		})
+		if err != nil {
+			log.Println("cannot release expired reservations:", err)
+			return released
+		}
+
+		released += len(result.Released)
+		if len(result.Released) < reservationSweepBatch {
+			return released
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SQLRevocationStore keeps revoked tokens in postgres
+type SQLRevocationStore struct {
+	querier Querier
+}
+
+// NewRevocationStore creates a new postgres backed revocation store
+func NewRevocationStore(querier Querier) *SQLRevocationStore {
+	return &SQLRevocationStore{
+		querier: querier,
+	}
+}
+
+// RevokeToken revokes a single token until it expires,
+// the revoked tokens already expired are pruned first as the memory store does
+func (store *SQLRevocationStore) RevokeToken(ctx context.Context, tokenID uuid.UUID, username string, expiredAt time.Time) error {
+	err := store.querier.DeleteExpiredRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	return store.querier.CreateRevokedToken(ctx, CreateRevokedTokenParams{
+		ID:        tokenID,
+		Username:  username,
+		ExpiresAt: expiredAt,
+	})
+}
+
+// RevokeAccount revokes every token of the username issued before the given time
+func (store *SQLRevocationStore) RevokeAccount(ctx context.Context, username string, before time.Time) error {
+	_, err := store.querier.UpsertAccountRevocation(ctx, UpsertAccountRevocationParams{
+		Username:      username,
+		RevokedBefore: before,
+	})
+	return err
+}
+
+// IsRevoked checks if the token was revoked, either by itself or through its account
+func (store *SQLRevocationStore) IsRevoked(ctx context.Context, tokenID uuid.UUID, username string, issuedAt time.Time) (bool, error) {
+	revoked, err := store.querier.IsTokenRevoked(ctx, tokenID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	revocation, err := store.querier.GetAccountRevocation(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return issuedAt.Before(revocation.RevokedBefore), nil
+}
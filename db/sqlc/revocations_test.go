@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRevokedToken(t *testing.T) {
+	account := mockCreateAccountLog(t)
+	tokenID := uuid.New()
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), tokenID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	arg := CreateRevokedTokenParams{
+		ID:        tokenID,
+		Username:  account.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	err = testQueries.CreateRevokedToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	// revoking the same token twice is not an error
+	err = testQueries.CreateRevokedToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), tokenID)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestUpsertAccountRevocation(t *testing.T) {
+	account := mockCreateAccountLog(t)
+	before := time.Now()
+
+	revocation1, err := testQueries.UpsertAccountRevocation(context.Background(), UpsertAccountRevocationParams{
+		Username:      account.Username,
+		RevokedBefore: before,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Username, revocation1.Username)
+	require.WithinDuration(t, before, revocation1.RevokedBefore, time.Second)
+
+	// an older cutoff keeps the latest one
+	revocation2, err := testQueries.UpsertAccountRevocation(context.Background(), UpsertAccountRevocationParams{
+		Username:      account.Username,
+		RevokedBefore: before.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, revocation1.RevokedBefore, revocation2.RevokedBefore, time.Millisecond)
+
+	revocation3, err := testQueries.GetAccountRevocation(context.Background(), account.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, revocation1.RevokedBefore, revocation3.RevokedBefore, time.Millisecond)
+}
+
+func TestSQLRevocationStore(t *testing.T) {
+	account := mockCreateAccountLog(t)
+	store := NewRevocationStore(testQueries)
+	ctx := context.Background()
+
+	tokenID := uuid.New()
+	issuedAt := time.Now()
+
+	revoked, err := store.IsRevoked(ctx, tokenID, account.Username, issuedAt)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	err = store.RevokeToken(ctx, tokenID, account.Username, issuedAt.Add(time.Minute))
+	require.NoError(t, err)
+
+	revoked, err = store.IsRevoked(ctx, tokenID, account.Username, issuedAt)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	err = store.RevokeAccount(ctx, account.Username, issuedAt.Add(time.Second))
+	require.NoError(t, err)
+
+	revoked, err = store.IsRevoked(ctx, uuid.New(), account.Username, issuedAt)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = store.IsRevoked(ctx, uuid.New(), account.Username, issuedAt.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestSQLRevocationStorePrunesExpiredTokens(t *testing.T) {
+	account := mockCreateAccountLog(t)
+	store := NewRevocationStore(testQueries)
+	ctx := context.Background()
+
+	expiredID := uuid.New()
+	err := testQueries.CreateRevokedToken(ctx, CreateRevokedTokenParams{
+		ID:        expiredID,
+		Username:  account.Username,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	tokenID := uuid.New()
+	err = store.RevokeToken(ctx, tokenID, account.Username, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	// the token past its expiry is not kept any longer
+	revoked, err := testQueries.IsTokenRevoked(ctx, expiredID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	revoked, err = testQueries.IsTokenRevoked(ctx, tokenID)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
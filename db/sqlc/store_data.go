@@ -3,33 +3,82 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 )
 
 // Store provided functions to exec db query
 type Store interface {
 	Querier
+	ListPokemonOrderData(ctx context.Context, arg ListPokemonOrderDataParams) ([]PokeOrder, error)
+	ListOrderDetailedData(ctx context.Context, arg ListOrderDetailedDataParams) ([]ListOrderDetailedDataRow, error)
 	OrderTx(ctx context.Context, arg OrderTxParams) (OrderTxResult, error)
-	CancelOrderTx(ctx context.Context, arg CancelOrderParam) (string, error)
+	CheckoutTx(ctx context.Context, arg CheckoutTxParams) (CheckoutTxResult, error)
+	CancelOrderTx(ctx context.Context, arg CancelOrderParam) (OrderTxResult, error)
+	UpdateOrderStatusTx(ctx context.Context, arg UpdateOrderStatusTxParams) (OrderTxResult, error)
+	RefundOrderTx(ctx context.Context, arg RefundOrderTxParams) (RefundOrderTxResult, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (ChangePasswordTxResult, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error)
+	ReserveStockTx(ctx context.Context, arg ReserveStockTxParams) (ReservationTxResult, error)
+	ReleaseReservationTx(ctx context.Context, arg ReservationTxParams) (ReservationTxResult, error)
+	ConvertReservationTx(ctx context.Context, arg ReservationTxParams) (ConvertReservationTxResult, error)
+	ReleaseExpiredReservationsTx(ctx context.Context, arg ReleaseExpiredReservationsTxParams) (ReleaseExpiredReservationsTxResult, error)
 }
 
 // Store provided functions to exec db query
 type SQLStore struct {
 	*Queries
 	db *sql.DB
+
+	// maxTxRetries and txRetryBackoff bound the retries of a transaction failing on a concurrent one
+	maxTxRetries   int
+	txRetryBackoff time.Duration
 }
 
 // Create New Store
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
-		db:      db,
-		Queries: New(db),
+		db:             db,
+		Queries:        New(db),
+		maxTxRetries:   DefaultTxMaxRetries,
+		txRetryBackoff: DefaultTxRetryBackoff,
 	}
 }
 
-// ExecTx executes a function within a database transaction
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// ExecTx executes a function within a database transaction with the given options,
+// the whole transaction is run again with a jittered backoff when it fails on a serialization failure or a deadlock
+func (store *SQLStore) execTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	for attempt := 0; ; attempt++ {
+		err := store.runTx(ctx, opts, fn)
+		if err == nil {
+			txMetrics.Add("committed", 1)
+			return nil
+		}
+
+		if !isRetryableTxError(err) {
+			txMetrics.Add("failed", 1)
+			return err
+		}
+
+		if attempt >= store.maxTxRetries {
+			txMetrics.Add("retries_exhausted", 1)
+			return err
+		}
+
+		txMetrics.Add("retried", 1)
+		select {
+		case <-ctx.Done():
+			txMetrics.Add("failed", 1)
+			return err
+		case <-time.After(txBackoff(store.txRetryBackoff, attempt)):
+		}
+	}
+}
+
+// runTx runs a single attempt of the transaction
+func (store *SQLStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -38,7 +87,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
 		}
 		return err
 	}
@@ -48,13 +97,15 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 
 // OrderTxParams contains input parameter of the transaction
 type OrderTxParams struct {
-	UserID    int64 `json:"user_id"`
-	ProductID int64 `json:"product_id"`
-	Quantity  int32 `json:"quantity"`
+	UserID        int64  `json:"user_id"`
+	ProductID     int64  `json:"product_id"`
+	Quantity      int32  `json:"quantity"`
+	PromotionCode string `json:"promotion_code"`
 }
 
 type OrderTxResult struct {
-	Order PokeOrder `json:"pokeorder"`
+	Order     PokeOrder       `json:"pokeorder"`
+	Discounts []OrderDiscount `json:"discounts,omitempty"`
 }
 
 type CancelOrderParam struct {
@@ -63,69 +114,330 @@ type CancelOrderParam struct {
 
 // OrderTx perform Order transaction of pokemon and put it into table poke_orders
 // It creates the order, add data in poke order, and update the pokemon stock based on pokemon id
+// The name, price and currency of the pokemon are copied on the order line as they are at purchase time
+// The discount of the promotion code, when given, is taken off the order and recorded on its line
+// The product row is locked until the end of the transaction so concurrent orders cannot oversell it
 func (store *SQLStore) OrderTx(ctx context.Context, arg OrderTxParams) (OrderTxResult, error) {
 	var result OrderTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execTx(ctx, serializableTx, func(q *Queries) error {
 		var err error
 
-		getPokeData, err := q.GetPokemonData(ctx, arg.ProductID)
+		getPokeData, err := q.GetPokemonDataForUpdate(ctx, arg.ProductID)
 		if err != nil {
 			return err
 		}
 
-		result.Order, err = q.InsertPokemonOrderData(ctx, InsertPokemonOrderDataParams{
-			UserID:      arg.UserID,
+		lines := []CreateOrderItemParams{{
 			ProductID:   arg.ProductID,
+			ProductName: getPokeData.PokeName,
 			Quantity:    arg.Quantity,
+			UnitPrice:   getPokeData.PokePrice,
+			Currency:    getPokeData.Currency,
 			TotalPrice:  int64(arg.Quantity) * getPokeData.PokePrice,
-			OrderDetail: "selling",
+		}}
+
+		var promo Promotion
+		if arg.PromotionCode != "" {
+			promo, _, err = applyPromotion(ctx, q, arg.PromotionCode, arg.UserID, []PokeProduct{getPokeData}, lines)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Order, err = q.InsertPokemonOrderData(ctx, InsertPokemonOrderDataParams{
+			UserID:        arg.UserID,
+			ProductID:     sql.NullInt64{Int64: arg.ProductID, Valid: true},
+			Quantity:      arg.Quantity,
+			TotalPrice:    lines[0].TotalPrice,
+			Currency:      getPokeData.Currency,
+			DiscountTotal: lines[0].DiscountAmount,
 		})
 		if err != nil {
 			return err
 		}
 
-		_, err = q.DeductPokemonStockData(ctx, DeductPokemonStockDataParams{
-			ID:     arg.ProductID,
-			Amount: int64(arg.Quantity),
+		lines[0].OrderID = result.Order.ID
+		item, err := q.CreateOrderItem(ctx, lines[0])
+		if err != nil {
+			return err
+		}
+
+		if arg.PromotionCode != "" {
+			result.Discounts, err = recordRedemption(ctx, q, promo, result.Order, []OrderItem{item})
+			if err != nil {
+				return err
+			}
+		}
+
+		return deductStock(ctx, q, getPokeData, arg.Quantity)
+	})
+
+	return result, err
+}
+
+// ErrEmptyCart is returned when checking out a cart without any item
+var ErrEmptyCart = errors.New("cart is empty")
+
+// ErrInsufficientStock is returned when an order asks for more than the stock of a product
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// deductStock takes the quantity off the stock of a product locked by the transaction,
+// the stock never goes below zero
+func deductStock(ctx context.Context, q *Queries, product PokeProduct, quantity int32) error {
+	_, err := q.DeductPokemonStockData(ctx, DeductPokemonStockDataParams{
+		ID:     product.ID,
+		Amount: int64(quantity),
+	})
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s has %d left, %d requested", ErrInsufficientStock, product.PokeName, product.PokeStock, quantity)
+	}
+	return err
+}
+
+// CheckoutTxParams contains input parameter of the checkout transaction
+type CheckoutTxParams struct {
+	UserID        int64  `json:"user_id"`
+	PromotionCode string `json:"promotion_code"`
+}
+
+type CheckoutTxResult struct {
+	Order     PokeOrder       `json:"pokeorder"`
+	Items     []OrderItem     `json:"items"`
+	Discounts []OrderDiscount `json:"discounts,omitempty"`
+}
+
+// CheckoutTx turns the cart of the user into a single order with one line per cart item
+// Every line is priced at the current product price, its stock is deducted and the cart is emptied
+// All the products of the cart have to be priced in the same currency
+// The discount of the promotion code, when given, is spread on the lines it covers
+func (store *SQLStore) CheckoutTx(ctx context.Context, arg CheckoutTxParams) (CheckoutTxResult, error) {
+	var result CheckoutTxResult
+
+	err := store.execTx(ctx, serializableTx, func(q *Queries) error {
+		cart, err := q.ListCartItems(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+		if len(cart) == 0 {
+			return ErrEmptyCart
+		}
+
+		// the cart is sorted by product, the rows are always locked in the same order
+		products := make([]PokeProduct, len(cart))
+		lines := make([]CreateOrderItemParams, len(cart))
+		order := InsertPokemonOrderDataParams{UserID: arg.UserID}
+		for i, item := range cart {
+			product, err := q.GetPokemonDataForUpdate(ctx, item.ProductID)
+			if err != nil {
+				return err
+			}
+			products[i] = product
+
+			if i > 0 && product.Currency != order.Currency {
+				return fmt.Errorf("%w: %s and %s", ErrMixedCurrency, order.Currency, product.Currency)
+			}
+			order.Currency = product.Currency
+
+			lines[i] = CreateOrderItemParams{
+				ProductID:   item.ProductID,
+				ProductName: product.PokeName,
+				Quantity:    item.Quantity,
+				UnitPrice:   product.PokePrice,
+				Currency:    product.Currency,
+				TotalPrice:  int64(item.Quantity) * product.PokePrice,
+			}
+			order.Quantity += item.Quantity
+			order.TotalPrice += lines[i].TotalPrice
+		}
+
+		var promo Promotion
+		if arg.PromotionCode != "" {
+			promo, order.DiscountTotal, err = applyPromotion(ctx, q, arg.PromotionCode, arg.UserID, products, lines)
+			if err != nil {
+				return err
+			}
+			order.TotalPrice -= order.DiscountTotal
+		}
+
+		result.Order, err = q.InsertPokemonOrderData(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		result.Items = make([]OrderItem, len(lines))
+		for i, line := range lines {
+			line.OrderID = result.Order.ID
+			result.Items[i], err = q.CreateOrderItem(ctx, line)
+			if err != nil {
+				return err
+			}
+
+			err = deductStock(ctx, q, products[i], line.Quantity)
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.PromotionCode != "" {
+			result.Discounts, err = recordRedemption(ctx, q, promo, result.Order, result.Items)
+			if err != nil {
+				return err
+			}
+		}
+
+		return q.ClearCart(ctx, arg.UserID)
+	})
+
+	return result, err
+}
+
+// CancelOrderTx perform cancellation transaction of a pending order and return its stock data into table poke_products
+func (store *SQLStore) CancelOrderTx(ctx context.Context, arg CancelOrderParam) (OrderTxResult, error) {
+	return store.UpdateOrderStatusTx(ctx, UpdateOrderStatusTxParams{
+		ID:     arg.ID,
+		Status: OrderStatusCancelled,
+	})
+}
+
+// UpdateOrderStatusTxParams contains input parameter of the order status transaction,
+// when the user is given an order of another user is not found
+type UpdateOrderStatusTxParams struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"user_id"`
+	Status string `json:"status"`
+}
+
+// UpdateOrderStatusTx moves the order to the given status when the transition is allowed,
+// the order row is locked so concurrent transitions are applied one after another.
+// Cancelling or refunding an order that was not fulfilled gives the quantity of every line back to the stock
+func (store *SQLStore) UpdateOrderStatusTx(ctx context.Context, arg UpdateOrderStatusTxParams) (OrderTxResult, error) {
+	var result OrderTxResult
+
+	err := store.execTx(ctx, serializableTx, func(q *Queries) error {
+		order, err := q.GetPokemonOrderDataForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if arg.UserID != 0 && order.UserID != arg.UserID {
+			return sql.ErrNoRows
+		}
+
+		err = validateOrderTransition(order.Status, arg.Status)
+		if err != nil {
+			return err
+		}
+
+		result.Order, err = q.UpdatePokemonOrderStatus(ctx, UpdatePokemonOrderStatusParams{
+			ID:     order.ID,
+			Status: arg.Status,
 		})
 		if err != nil {
 			return err
 		}
 
-		return err
+		if !restocksOrder(order.Status, arg.Status) {
+			return nil
+		}
 
+		items, err := q.ListOrderItems(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			// the quantity given back by partial refunds was restocked already
+			remaining := item.Quantity - item.RefundedQuantity
+			if remaining == 0 {
+				continue
+			}
+
+			_, err = q.AddPokemonStockData(ctx, AddPokemonStockDataParams{
+				ID:     item.ProductID,
+				Amount: int64(remaining),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 
 	return result, err
 }
 
-// CancelOrderTx perform cancellation transaction of pokemon and return it stock data into table poke_orders
-// It delete in poke order, and update the pokemon stock based on pokemon id
-func (store *SQLStore) CancelOrderTx(ctx context.Context, arg CancelOrderParam) (string, error) {
-	var result string
+// ResetPasswordTxParams contains input parameter of the password reset transaction
+type ChangePasswordTxParams struct {
+	Username       string    `json:"username"`
+	HashedPassword string    `json:"hashed_password"`
+	ChangedAt      time.Time `json:"changed_at"`
+}
+
+type ChangePasswordTxResult struct {
+	Account Account `json:"account"`
+}
+
+// ChangePasswordTx update the password of an account and block its sessions in the same transaction,
+// so the password is never changed without blocking them, the caller revokes the tokens issued before the change
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (ChangePasswordTxResult, error) {
+	var result ChangePasswordTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execTx(ctx, nil, func(q *Queries) error {
 		var err error
+		result.Account, err = q.UpdateAccountPassword(ctx, UpdateAccountPasswordParams{
+			Username:          arg.Username,
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangetAt: sql.NullTime{Time: arg.ChangedAt, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
 
-		orderData, err := q.GetPokemonOrderData(ctx, arg.ID)
+		return q.BlockAccountSessions(ctx, arg.Username)
+	})
+
+	return result, err
+}
+
+type ResetPasswordTxParams struct {
+	HashedToken    string    `json:"hashed_token"`
+	HashedPassword string    `json:"hashed_password"`
+	ChangedAt      time.Time `json:"changed_at"`
+}
+
+type ResetPasswordTxResult struct {
+	Account Account `json:"account"`
+}
+
+// ResetPasswordTx consumes a valid reset token and update the password of its account
+// Every other pending reset token of the account is expired as well and its sessions are blocked in the same
+// transaction, so the token is never used without blocking them, the caller revokes the tokens issued before the change
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		reset, err := q.UsePasswordReset(ctx, arg.HashedToken)
 		if err != nil {
 			return err
 		}
 
-		_, err = q.AddPokemonStockData(ctx, AddPokemonStockDataParams{
-			ID:     orderData.ProductID,
-			Amount: int64(orderData.Quantity),
+		result.Account, err = q.UpdateAccountPassword(ctx, UpdateAccountPasswordParams{
+			Username:          reset.Username,
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangetAt: sql.NullTime{Time: arg.ChangedAt, Valid: true},
 		})
 		if err != nil {
-			result = "Error return stock"
 			return err
 		}
 
-		return err
+		err = q.BlockAccountSessions(ctx, reset.Username)
+		if err != nil {
+			return err
+		}
 
+		return q.ExpirePasswordResets(ctx, reset.Username)
 	})
-	result = "stock returned"
 
 	return result, err
 }
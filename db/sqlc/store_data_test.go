@@ -2,7 +2,10 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/gunhachi/poke-blackmarket/util"
 	"github.com/stretchr/testify/require"
@@ -14,14 +17,25 @@ func mockOrderTx(t *testing.T, user User, pokemon PokeProduct) OrderTxResult {
 	result, err := order.OrderTx(context.Background(), OrderTxParams{
 		UserID:    user.ID,
 		ProductID: pokemon.ID,
-		Quantity:  int32(util.RandomAmount()),
+		Quantity:  int32(util.RandomInt(1, 10)),
 	})
 
 	require.NoError(t, err)
 	require.NotEmpty(t, result)
 
 	require.Equal(t, user.ID, result.Order.UserID)
-	require.Equal(t, pokemon.ID, result.Order.ProductID)
+	require.Equal(t, pokemon.ID, result.Order.ProductID.Int64)
+
+	items, err := testQueries.ListOrderItems(context.Background(), result.Order.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, pokemon.ID, items[0].ProductID)
+	require.Equal(t, result.Order.Quantity, items[0].Quantity)
+	require.Equal(t, pokemon.PokePrice, items[0].UnitPrice)
+	require.Equal(t, pokemon.PokeName, items[0].ProductName)
+	require.Equal(t, pokemon.Currency, items[0].Currency)
+	require.Equal(t, pokemon.Currency, result.Order.Currency)
+	require.Equal(t, result.Order.TotalPrice, items[0].TotalPrice)
 	return result
 }
 
@@ -31,6 +45,72 @@ func TestOrdertx(t *testing.T) {
 	mockOrderTx(t, user, pokemon)
 }
 
+func TestOrderTxInsufficientStock(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+
+	_, err := store.OrderTx(context.Background(), OrderTxParams{
+		UserID:    user.ID,
+		ProductID: pokemon.ID,
+		Quantity:  int32(pokemon.PokeStock) + 1,
+	})
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+
+	product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock, product.PokeStock)
+}
+
+func TestOrderTxConcurrent(t *testing.T) {
+	n := 20
+
+	// every order conflicts on the same product, each round of retries lets one of them through
+	store := NewStore(testDB).(*SQLStore)
+	store.maxTxRetries = n
+
+	user := mockCreateUserAccount(t)
+	pokemon, err := testQueries.UpdatePokemonData(context.Background(), UpdatePokemonDataParams{
+		ID:        mockRandomData(t).ID,
+		Status:    util.RandomUser(),
+		PokePrice: util.RandomAmount(),
+		PokeStock: 5,
+	})
+	require.NoError(t, err)
+
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.OrderTx(context.Background(), OrderTxParams{
+				UserID:    user.ID,
+				ProductID: pokemon.ID,
+				Quantity:  1,
+			})
+			errs <- err
+		}()
+	}
+
+	var ordered, rejected int64
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if errors.Is(err, ErrInsufficientStock) {
+			rejected++
+			continue
+		}
+		require.NoError(t, err)
+		ordered++
+	}
+
+	// only the stock there was has been sold, the rest is rejected
+	require.Equal(t, pokemon.PokeStock, ordered)
+	require.Equal(t, int64(n)-pokemon.PokeStock, rejected)
+
+	product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Zero(t, product.PokeStock)
+}
+
 func TestCancelOrdertx(t *testing.T) {
 	order := NewStore(testDB)
 
@@ -44,5 +124,247 @@ func TestCancelOrdertx(t *testing.T) {
 
 	require.NoError(t, err)
 	require.NotEmpty(t, result)
+	require.Equal(t, OrderStatusCancelled, result.Order.Status)
+	require.True(t, result.Order.CancelledAt.Valid)
+
+	restocked, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock, restocked.PokeStock)
+
+	_, err = order.CancelOrderTx(context.Background(), CancelOrderParam{
+		ID: data.Order.ID,
+	})
+	require.True(t, errors.Is(err, ErrInvalidOrderTransition))
+
+	again, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock, again.PokeStock)
+}
+
+func TestUpdateOrderStatusTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	data := mockOrderTx(t, user, pokemon)
+
+	for _, status := range []string{OrderStatusPaid, OrderStatusFulfilled, OrderStatusRefunded} {
+		result, err := store.UpdateOrderStatusTx(context.Background(), UpdateOrderStatusTxParams{
+			ID:     data.Order.ID,
+			Status: status,
+		})
+		require.NoError(t, err)
+		require.Equal(t, status, result.Order.Status)
+	}
+
+	// the pokemon were handed over before the refund, the stock is not given back
+	product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock-int64(data.Order.Quantity), product.PokeStock)
+
+	_, err = store.UpdateOrderStatusTx(context.Background(), UpdateOrderStatusTxParams{
+		ID:     data.Order.ID,
+		Status: OrderStatusPaid,
+	})
+	require.True(t, errors.Is(err, ErrInvalidOrderTransition))
+
+	_, err = store.UpdateOrderStatusTx(context.Background(), UpdateOrderStatusTxParams{
+		ID:     data.Order.ID + 1000000,
+		Status: OrderStatusPaid,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// an order of another user is not found
+	other := mockOrderTx(t, mockCreateUserAccount(t), pokemon)
+	_, err = store.UpdateOrderStatusTx(context.Background(), UpdateOrderStatusTxParams{
+		ID:     other.Order.ID,
+		UserID: user.ID,
+		Status: OrderStatusCancelled,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestCheckoutTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemons := []PokeProduct{mockRandomData(t), mockRandomData(t)}
+
+	_, err := store.CheckoutTx(context.Background(), CheckoutTxParams{UserID: user.ID})
+	require.True(t, errors.Is(err, ErrEmptyCart))
+
+	var quantity int32
+	var totalPrice int64
+	for i, pokemon := range pokemons {
+		item := mockAddCartItem(t, user, pokemon, int32(i+1))
+		quantity += item.Quantity
+		totalPrice += int64(item.Quantity) * pokemon.PokePrice
+	}
+
+	result, err := store.CheckoutTx(context.Background(), CheckoutTxParams{UserID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, result.Order.UserID)
+	require.False(t, result.Order.ProductID.Valid)
+	require.Equal(t, quantity, result.Order.Quantity)
+	require.Equal(t, totalPrice, result.Order.TotalPrice)
+	require.Equal(t, OrderStatusPending, result.Order.Status)
+	require.Len(t, result.Items, len(pokemons))
 
+	for i, pokemon := range pokemons {
+		require.Equal(t, result.Order.ID, result.Items[i].OrderID)
+		require.Equal(t, pokemon.ID, result.Items[i].ProductID)
+		require.Equal(t, pokemon.PokePrice, result.Items[i].UnitPrice)
+
+		product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+		require.NoError(t, err)
+		require.Equal(t, pokemon.PokeStock-int64(result.Items[i].Quantity), product.PokeStock)
+	}
+
+	cart, err := testQueries.ListCartItems(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, cart)
+
+	// a line asking for more than the stock rolls the whole checkout back
+	mockAddCartItem(t, user, pokemons[0], 1)
+	mockAddCartItem(t, user, pokemons[1], int32(pokemons[1].PokeStock))
+	_, err = store.CheckoutTx(context.Background(), CheckoutTxParams{UserID: user.ID})
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+
+	cart, err = testQueries.ListCartItems(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, cart, 2)
+
+	err = testQueries.ClearCart(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	// cancelling gives the stock of every line back
+	_, err = store.CancelOrderTx(context.Background(), CancelOrderParam{ID: result.Order.ID})
+	require.NoError(t, err)
+
+	for _, pokemon := range pokemons {
+		product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+		require.NoError(t, err)
+		require.Equal(t, pokemon.PokeStock, product.PokeStock)
+	}
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	session := mockCreateSession(t)
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	arg := ChangePasswordTxParams{
+		Username:       session.Username,
+		HashedPassword: hashedPassword,
+		ChangedAt:      time.Now(),
+	}
+	result, err := store.ChangePasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, session.Username, result.Account.Username)
+	require.Equal(t, hashedPassword, result.Account.HashedPassword)
+	require.WithinDuration(t, arg.ChangedAt, result.Account.PasswordChangetAt.Time, time.Second)
+
+	// the sessions of the account are blocked with the change
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	arg.Username = util.RandomUser()
+	_, err = store.ChangePasswordTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	session := mockCreateSession(t)
+	account, err := testQueries.GetAccountLog(context.Background(), session.Username)
+	require.NoError(t, err)
+	reset, _ := mockCreatePasswordReset(t, account, time.Now().Add(time.Hour))
+	other, _ := mockCreatePasswordReset(t, account, time.Now().Add(time.Hour))
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	arg := ResetPasswordTxParams{
+		HashedToken:    reset.HashedToken,
+		HashedPassword: hashedPassword,
+		ChangedAt:      time.Now(),
+	}
+	result, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, account.Username, result.Account.Username)
+	require.Equal(t, hashedPassword, result.Account.HashedPassword)
+	require.WithinDuration(t, arg.ChangedAt, result.Account.PasswordChangetAt.Time, time.Second)
+
+	// the sessions of the account are blocked with the change
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	arg.HashedToken = other.HashedToken
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestCheckoutTxMixedCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon, err := testQueries.CreatePokemonData(context.Background(), CreatePokemonDataParams{
+		PokeName:  util.RandomUser(),
+		Status:    util.RandomUser(),
+		PokeStock: util.RandomInt(10, 1000),
+		PokePrice: util.RandomAmount(),
+		Currency:  "EUR",
+	})
+	require.NoError(t, err)
+
+	mockAddCartItem(t, user, pokemon, 1)
+	mockAddCartItem(t, user, mockRandomData(t), 1)
+
+	_, err = store.CheckoutTx(context.Background(), CheckoutTxParams{UserID: user.ID})
+	require.True(t, errors.Is(err, ErrMixedCurrency))
+
+	cart, err := testQueries.ListCartItems(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, cart, 2)
+}
+
+func TestOrderTxPriceSnapshot(t *testing.T) {
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	result := mockOrderTx(t, user, pokemon)
+
+	// the pokemon is repriced after the purchase
+	_, err := testQueries.UpdatePokemonData(context.Background(), UpdatePokemonDataParams{
+		ID:        pokemon.ID,
+		Status:    pokemon.Status,
+		PokePrice: pokemon.PokePrice + 100,
+		PokeStock: pokemon.PokeStock,
+	})
+	require.NoError(t, err)
+
+	items, err := testQueries.ListOrderItems(context.Background(), result.Order.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, pokemon.PokePrice, items[0].UnitPrice)
+	require.Equal(t, pokemon.PokeName, items[0].ProductName)
+
+	detailed, err := testQueries.ListOrderDetailedData(context.Background(), ListOrderDetailedDataParams{
+		UserID: sql.NullInt64{Int64: user.ID, Valid: true},
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, detailed, 1)
+	require.Equal(t, pokemon.PokeName, detailed[0].PokeName)
+	require.Equal(t, pokemon.Currency, detailed[0].Currency)
+	require.Equal(t, result.Order.TotalPrice, detailed[0].TotalPrice)
 }
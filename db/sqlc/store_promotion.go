@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Discount types of a promotion
+const (
+	PromotionPercentage  = "percentage"
+	PromotionFixedAmount = "fixed_amount"
+)
+
+var (
+	// ErrPromotionNotFound is returned when ordering with an unknown promotion code
+	ErrPromotionNotFound = errors.New("promotion code not found")
+	// ErrPromotionNotActive is returned when ordering before the promotion starts or after it ends
+	ErrPromotionNotActive = errors.New("promotion is not active")
+	// ErrPromotionNotApplicable is returned when the promotion is not open to the user or covers no line of the order
+	ErrPromotionNotApplicable = errors.New("promotion does not apply to the order")
+	// ErrPromotionExhausted is returned when the promotion reached its max redemptions
+	ErrPromotionExhausted = errors.New("promotion is fully redeemed")
+	// ErrPromotionUserLimit is returned when the user reached the max redemptions per user of the promotion
+	ErrPromotionUserLimit = errors.New("promotion is fully redeemed by the user")
+)
+
+// applyPromotion checks the promotion of the code for the order of the user and takes its discount off the lines,
+// the total discount is returned. The promotion row is locked until the end of the transaction,
+// so its redemptions are counted one order after another
+func applyPromotion(ctx context.Context, q *Queries, code string, userID int64, products []PokeProduct, lines []CreateOrderItemParams) (Promotion, int64, error) {
+	promo, err := q.GetPromotionByCodeForUpdate(ctx, code)
+	if err == sql.ErrNoRows {
+		return promo, 0, fmt.Errorf("%w: %s", ErrPromotionNotFound, code)
+	}
+	if err != nil {
+		return promo, 0, err
+	}
+
+	now := time.Now()
+	if now.Before(promo.StartsAt) || (promo.EndsAt.Valid && !now.Before(promo.EndsAt.Time)) {
+		return promo, 0, fmt.Errorf("%w: %s", ErrPromotionNotActive, code)
+	}
+
+	if promo.MaxRedemptions.Valid && promo.RedemptionCount >= promo.MaxRedemptions.Int32 {
+		return promo, 0, fmt.Errorf("%w: %s", ErrPromotionExhausted, code)
+	}
+
+	if promo.UserRole.Valid {
+		user, err := q.GetUserAccount(ctx, userID)
+		if err != nil {
+			return promo, 0, err
+		}
+		if user.UserRole != promo.UserRole.String {
+			return promo, 0, fmt.Errorf("%w: %s is only for %s users", ErrPromotionNotApplicable, code, promo.UserRole.String)
+		}
+	}
+
+	if promo.MaxRedemptionsPerUser.Valid {
+		count, err := q.CountUserPromotionRedemptions(ctx, CountUserPromotionRedemptionsParams{
+			PromotionID: promo.ID,
+			UserID:      userID,
+		})
+		if err != nil {
+			return promo, 0, err
+		}
+		if count >= int64(promo.MaxRedemptionsPerUser.Int32) {
+			return promo, 0, fmt.Errorf("%w: %s", ErrPromotionUserLimit, code)
+		}
+	}
+
+	var total int64
+	for i, discount := range promotionDiscounts(promo, products, lines) {
+		lines[i].DiscountAmount = discount
+		lines[i].TotalPrice -= discount
+		total += discount
+	}
+	if total == 0 {
+		return promo, 0, fmt.Errorf("%w: %s", ErrPromotionNotApplicable, code)
+	}
+
+	return promo, total, nil
+}
+
+// promotionDiscounts works out the discount of every line of the order,
+// a fixed amount is taken off the lines in scope one after another and never exceeds their total
+func promotionDiscounts(promo Promotion, products []PokeProduct, lines []CreateOrderItemParams) []int64 {
+	discounts := make([]int64, len(lines))
+	remaining := promo.DiscountValue
+	for i, line := range lines {
+		if promo.ProductID.Valid && promo.ProductID.Int64 != products[i].ID {
+			continue
+		}
+		if promo.PokeType.Valid && promo.PokeType.String != products[i].PokeType {
+			continue
+		}
+
+		switch promo.DiscountType {
+		case PromotionPercentage:
+			discounts[i] = line.TotalPrice * promo.DiscountValue / 100
+		case PromotionFixedAmount:
+			if promo.Currency.String != line.Currency {
+				continue
+			}
+			discounts[i] = remaining
+			if discounts[i] > line.TotalPrice {
+				discounts[i] = line.TotalPrice
+			}
+			remaining -= discounts[i]
+		}
+	}
+	return discounts
+}
+
+// recordRedemption counts the order as a redemption of the promotion and records the discount of its lines,
+// the promotion is not redeemed past its max redemptions even by orders placed at the same time
+func recordRedemption(ctx context.Context, q *Queries, promo Promotion, order PokeOrder, items []OrderItem) ([]OrderDiscount, error) {
+	_, err := q.RedeemPromotion(ctx, promo.ID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrPromotionExhausted, promo.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.CreatePromotionRedemption(ctx, CreatePromotionRedemptionParams{
+		PromotionID: promo.ID,
+		UserID:      order.UserID,
+		OrderID:     order.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	discounts := []OrderDiscount{}
+	for _, item := range items {
+		if item.DiscountAmount == 0 {
+			continue
+		}
+
+		discount, err := q.CreateOrderDiscount(ctx, CreateOrderDiscountParams{
+			OrderID:     order.ID,
+			OrderItemID: item.ID,
+			PromotionID: promo.ID,
+			Code:        promo.Code,
+			Amount:      item.DiscountAmount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, discount)
+	}
+	return discounts, nil
+}
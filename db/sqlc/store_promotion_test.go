@@ -0,0 +1,271 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPromotion creates a promotion running from now, the code and author are filled in when empty
+func mockPromotion(t *testing.T, arg CreatePromotionParams) Promotion {
+	if arg.Code == "" {
+		arg.Code = strings.ToUpper(util.RandomString(10))
+	}
+	if arg.StartsAt.IsZero() {
+		arg.StartsAt = time.Now().Add(-time.Minute)
+	}
+	arg.CreatedBy = util.RandomUser()
+
+	promo, err := testQueries.CreatePromotion(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, promo.ID)
+	require.Equal(t, arg.Code, promo.Code)
+	require.Equal(t, arg.DiscountType, promo.DiscountType)
+	require.Equal(t, arg.DiscountValue, promo.DiscountValue)
+	require.Zero(t, promo.RedemptionCount)
+
+	return promo
+}
+
+// mockPricedData creates a pokemon of the type, priced high enough for any discount to take something off
+func mockPricedData(t *testing.T, pokeType string) PokeProduct {
+	pokemon := mockRandomData(t)
+	pokemon, err := testQueries.UpdatePokemonData(context.Background(), UpdatePokemonDataParams{
+		ID:        pokemon.ID,
+		Status:    pokemon.Status,
+		PokePrice: util.RandomInt(100, 1000),
+		PokeStock: pokemon.PokeStock,
+		PokeType:  sql.NullString{String: pokeType, Valid: pokeType != ""},
+	})
+	require.NoError(t, err)
+	return pokemon
+}
+
+func TestOrderTxPromotion(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockPricedData(t, "")
+	promo := mockPromotion(t, CreatePromotionParams{
+		DiscountType:  PromotionPercentage,
+		DiscountValue: 20,
+		ProductID:     sql.NullInt64{Int64: pokemon.ID, Valid: true},
+	})
+
+	result, err := store.OrderTx(context.Background(), OrderTxParams{
+		UserID:        user.ID,
+		ProductID:     pokemon.ID,
+		Quantity:      3,
+		PromotionCode: promo.Code,
+	})
+	require.NoError(t, err)
+
+	gross := 3 * pokemon.PokePrice
+	discount := gross * 20 / 100
+	require.Equal(t, discount, result.Order.DiscountTotal)
+	require.Equal(t, gross-discount, result.Order.TotalPrice)
+
+	require.Len(t, result.Discounts, 1)
+	require.Equal(t, promo.ID, result.Discounts[0].PromotionID)
+	require.Equal(t, promo.Code, result.Discounts[0].Code)
+	require.Equal(t, discount, result.Discounts[0].Amount)
+
+	items, err := testQueries.ListOrderItems(context.Background(), result.Order.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, pokemon.PokePrice, items[0].UnitPrice)
+	require.Equal(t, discount, items[0].DiscountAmount)
+	require.Equal(t, gross-discount, items[0].TotalPrice)
+	require.Equal(t, items[0].ID, result.Discounts[0].OrderItemID)
+
+	redeemed, err := testQueries.GetPromotion(context.Background(), promo.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), redeemed.RedemptionCount)
+
+	// another pokemon is not covered by the promotion
+	_, err = store.OrderTx(context.Background(), OrderTxParams{
+		UserID:        user.ID,
+		ProductID:     mockPricedData(t, "").ID,
+		Quantity:      1,
+		PromotionCode: promo.Code,
+	})
+	require.True(t, errors.Is(err, ErrPromotionNotApplicable))
+
+	_, err = store.OrderTx(context.Background(), OrderTxParams{
+		UserID:        user.ID,
+		ProductID:     pokemon.ID,
+		Quantity:      1,
+		PromotionCode: "UNKNOWN" + promo.Code,
+	})
+	require.True(t, errors.Is(err, ErrPromotionNotFound))
+
+	// the refunds of a discounted line add up to what was paid for it
+	var refunded int64
+	for i := 0; i < 3; i++ {
+		refund, err := store.RefundOrderTx(context.Background(), RefundOrderTxParams{
+			OrderID:    result.Order.ID,
+			Quantity:   1,
+			Reason:     util.RandomString(12),
+			RefundedBy: util.RandomUser(),
+		})
+		require.NoError(t, err)
+		refunded += refund.Refund.Amount
+	}
+	require.Equal(t, result.Order.TotalPrice, refunded)
+}
+
+func TestCheckoutTxPromotionFixedAmount(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokeType := util.RandomString(8)
+	pokemons := []PokeProduct{mockPricedData(t, pokeType), mockPricedData(t, pokeType), mockPricedData(t, "")}
+	for _, pokemon := range pokemons {
+		mockAddCartItem(t, user, pokemon, 1)
+	}
+
+	// the amount is more than the first pokemon of the type, the rest is taken off the second one
+	amount := pokemons[0].PokePrice + 1
+	promo := mockPromotion(t, CreatePromotionParams{
+		DiscountType:  PromotionFixedAmount,
+		DiscountValue: amount,
+		Currency:      sql.NullString{String: DefaultCurrency, Valid: true},
+		PokeType:      sql.NullString{String: pokeType, Valid: true},
+	})
+
+	result, err := store.CheckoutTx(context.Background(), CheckoutTxParams{
+		UserID:        user.ID,
+		PromotionCode: promo.Code,
+	})
+	require.NoError(t, err)
+	require.Equal(t, amount, result.Order.DiscountTotal)
+
+	var gross int64
+	discounts := map[int64]int64{}
+	for _, item := range result.Items {
+		gross += item.UnitPrice
+		discounts[item.ProductID] = item.DiscountAmount
+	}
+	require.Equal(t, gross-amount, result.Order.TotalPrice)
+	require.Equal(t, pokemons[0].PokePrice, discounts[pokemons[0].ID])
+	require.Equal(t, int64(1), discounts[pokemons[1].ID])
+	require.Zero(t, discounts[pokemons[2].ID])
+	require.Len(t, result.Discounts, 2)
+
+	stored, err := testQueries.ListOrderDiscounts(context.Background(), result.Order.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Discounts, stored)
+}
+
+func TestOrderTxPromotionLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockPricedData(t, "")
+	order := func(promo Promotion, user User) error {
+		_, err := store.OrderTx(context.Background(), OrderTxParams{
+			UserID:        user.ID,
+			ProductID:     pokemon.ID,
+			Quantity:      1,
+			PromotionCode: promo.Code,
+		})
+		return err
+	}
+
+	perUser := mockPromotion(t, CreatePromotionParams{
+		DiscountType:          PromotionPercentage,
+		DiscountValue:         10,
+		MaxRedemptionsPerUser: sql.NullInt32{Int32: 1, Valid: true},
+	})
+	require.NoError(t, order(perUser, user))
+	require.True(t, errors.Is(order(perUser, user), ErrPromotionUserLimit))
+	require.NoError(t, order(perUser, mockCreateUserAccount(t)))
+
+	global := mockPromotion(t, CreatePromotionParams{
+		DiscountType:   PromotionPercentage,
+		DiscountValue:  10,
+		MaxRedemptions: sql.NullInt32{Int32: 1, Valid: true},
+	})
+	require.NoError(t, order(global, user))
+	require.True(t, errors.Is(order(global, mockCreateUserAccount(t)), ErrPromotionExhausted))
+
+	ended := mockPromotion(t, CreatePromotionParams{
+		DiscountType:  PromotionPercentage,
+		DiscountValue: 10,
+		StartsAt:      time.Now().Add(-time.Hour),
+		EndsAt:        sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.True(t, errors.Is(order(ended, user), ErrPromotionNotActive))
+
+	leads := mockPromotion(t, CreatePromotionParams{
+		DiscountType:  PromotionPercentage,
+		DiscountValue: 10,
+		UserRole:      sql.NullString{String: "LEAD", Valid: true},
+	})
+	require.True(t, errors.Is(order(leads, user), ErrPromotionNotApplicable))
+
+	// a rejected promotion leaves the stock as it was
+	product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock-3, product.PokeStock)
+}
+
+func TestOrderTxPromotionConcurrent(t *testing.T) {
+	n := 10
+	limit := int32(3)
+
+	// every order conflicts on the same promotion, each round of retries lets one of them through
+	store := NewStore(testDB).(*SQLStore)
+	store.maxTxRetries = n
+
+	pokemon := mockPricedData(t, "")
+	promo := mockPromotion(t, CreatePromotionParams{
+		DiscountType:   PromotionPercentage,
+		DiscountValue:  50,
+		MaxRedemptions: sql.NullInt32{Int32: limit, Valid: true},
+	})
+
+	users := make([]User, n)
+	for i := range users {
+		users[i] = mockCreateUserAccount(t)
+	}
+
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		user := users[i]
+		go func() {
+			_, err := store.OrderTx(context.Background(), OrderTxParams{
+				UserID:        user.ID,
+				ProductID:     pokemon.ID,
+				Quantity:      1,
+				PromotionCode: promo.Code,
+			})
+			errs <- err
+		}()
+	}
+
+	var redeemed, rejected int32
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if errors.Is(err, ErrPromotionExhausted) {
+			rejected++
+			continue
+		}
+		require.NoError(t, err)
+		redeemed++
+	}
+
+	// only the max redemptions went through, the rest is rejected
+	require.Equal(t, limit, redeemed)
+	require.Equal(t, int32(n)-limit, rejected)
+
+	stored, err := testQueries.GetPromotion(context.Background(), promo.ID)
+	require.NoError(t, err)
+	require.Equal(t, limit, stored.RedemptionCount)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrRefundItemRequired is returned when refunding an order of several lines without choosing one
+	ErrRefundItemRequired = errors.New("the order has several lines, the item to refund is required")
+	// ErrRefundExceedsQuantity is returned when refunding more than what is left on the order line
+	ErrRefundExceedsQuantity = errors.New("refund exceeds the quantity left on the order line")
+)
+
+// RefundOrderTxParams contains input parameter of the refund transaction,
+// the item is optional on an order of a single line
+type RefundOrderTxParams struct {
+	OrderID    int64  `json:"order_id"`
+	ItemID     int64  `json:"item_id"`
+	Quantity   int32  `json:"quantity"`
+	Reason     string `json:"reason"`
+	RefundedBy string `json:"refunded_by"`
+}
+
+type RefundOrderTxResult struct {
+	Order  PokeOrder   `json:"pokeorder"`
+	Item   OrderItem   `json:"item"`
+	Refund OrderRefund `json:"refund"`
+}
+
+// RefundOrderTx gives back part of an order line, the refund is recorded with its reason and author,
+// the returned quantity goes back to the stock and the net total of the order drops by what was paid for it,
+// a fulfilled order included since only the refunded quantity was handed back.
+// A pending order is partially cancelled the same way, the order is closed once every line is refunded
+func (store *SQLStore) RefundOrderTx(ctx context.Context, arg RefundOrderTxParams) (RefundOrderTxResult, error) {
+	var result RefundOrderTxResult
+
+	err := store.execTx(ctx, serializableTx, func(q *Queries) error {
+		order, err := q.GetPokemonOrderDataForUpdate(ctx, arg.OrderID)
+		if err != nil {
+			return err
+		}
+
+		closing := closingStatus(order.Status)
+		err = validateOrderTransition(order.Status, closing)
+		if err != nil {
+			return err
+		}
+
+		items, err := q.ListOrderItems(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		item, err := refundedItem(items, arg.ItemID)
+		if err != nil {
+			return err
+		}
+
+		if arg.Quantity > item.Quantity-item.RefundedQuantity {
+			return fmt.Errorf("%w: %d left, %d requested", ErrRefundExceedsQuantity, item.Quantity-item.RefundedQuantity, arg.Quantity)
+		}
+
+		result.Item, err = q.RefundOrderItem(ctx, RefundOrderItemParams{
+			ID:       item.ID,
+			Quantity: arg.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddPokemonStockData(ctx, AddPokemonStockDataParams{
+			ID:     item.ProductID,
+			Amount: int64(arg.Quantity),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Refund, err = q.CreateOrderRefund(ctx, CreateOrderRefundParams{
+			OrderID:     order.ID,
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			Quantity:    arg.Quantity,
+			Amount:      refundAmount(item, arg.Quantity),
+			Reason:      arg.Reason,
+			OrderStatus: order.Status,
+			RefundedBy:  arg.RefundedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Order, err = q.AddPokemonOrderRefundedTotal(ctx, AddPokemonOrderRefundedTotalParams{
+			ID:     order.ID,
+			Amount: result.Refund.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, line := range items {
+			if line.ID != item.ID && line.RefundedQuantity < line.Quantity {
+				return nil
+			}
+		}
+		if result.Item.RefundedQuantity < result.Item.Quantity {
+			return nil
+		}
+
+		// every line is refunded, the order is closed
+		result.Order, err = q.UpdatePokemonOrderStatus(ctx, UpdatePokemonOrderStatusParams{
+			ID:     order.ID,
+			Status: closing,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// refundedItem picks the order line to refund, a line of another order is not found
+func refundedItem(items []OrderItem, itemID int64) (OrderItem, error) {
+	if itemID == 0 {
+		if len(items) != 1 {
+			return OrderItem{}, ErrRefundItemRequired
+		}
+		return items[0], nil
+	}
+
+	for _, item := range items {
+		if item.ID == itemID {
+			return item, nil
+		}
+	}
+	return OrderItem{}, sql.ErrNoRows
+}
+
+// refundAmount is the share of the total price of the line for the quantity, the amounts of
+// successive refunds add up to the total price of the line whatever the rounding of its discount
+func refundAmount(item OrderItem, quantity int32) int64 {
+	paid := func(refunded int32) int64 {
+		return int64(refunded) * item.TotalPrice / int64(item.Quantity)
+	}
+	return paid(item.RefundedQuantity+quantity) - paid(item.RefundedQuantity)
+}
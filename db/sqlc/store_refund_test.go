@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundOrderTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+
+	order, err := store.OrderTx(context.Background(), OrderTxParams{
+		UserID:    user.ID,
+		ProductID: pokemon.ID,
+		Quantity:  3,
+	})
+	require.NoError(t, err)
+
+	_, err = store.UpdateOrderStatusTx(context.Background(), UpdateOrderStatusTxParams{
+		ID:     order.Order.ID,
+		Status: OrderStatusPaid,
+	})
+	require.NoError(t, err)
+
+	arg := RefundOrderTxParams{
+		OrderID:    order.Order.ID,
+		Quantity:   1,
+		Reason:     util.RandomString(12),
+		RefundedBy: util.RandomUser(),
+	}
+	result, err := store.RefundOrderTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, order.Order.ID, result.Refund.OrderID)
+	require.Equal(t, result.Item.ID, result.Refund.OrderItemID)
+	require.Equal(t, pokemon.ID, result.Refund.ProductID)
+	require.Equal(t, int32(1), result.Refund.Quantity)
+	require.Equal(t, pokemon.PokePrice, result.Refund.Amount)
+	require.Equal(t, arg.Reason, result.Refund.Reason)
+	require.Equal(t, arg.RefundedBy, result.Refund.RefundedBy)
+	require.Equal(t, OrderStatusPaid, result.Refund.OrderStatus)
+
+	require.Equal(t, int32(1), result.Item.RefundedQuantity)
+	require.Equal(t, OrderStatusPaid, result.Order.Status)
+	require.Equal(t, pokemon.PokePrice, result.Order.RefundedTotal)
+	require.Equal(t, order.Order.TotalPrice-pokemon.PokePrice, result.Order.NetTotal)
+
+	product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock-2, product.PokeStock)
+
+	// refunding more than what is left changes nothing
+	arg.Quantity = 3
+	_, err = store.RefundOrderTx(context.Background(), arg)
+	require.True(t, errors.Is(err, ErrRefundExceedsQuantity))
+
+	// the last of the quantity closes the order
+	arg.Quantity = 2
+	result, err = store.RefundOrderTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusRefunded, result.Order.Status)
+	require.True(t, result.Order.RefundedAt.Valid)
+	require.Equal(t, int64(0), result.Order.NetTotal)
+
+	product, err = testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock, product.PokeStock)
+
+	refunds, err := testQueries.ListOrderRefunds(context.Background(), order.Order.ID)
+	require.NoError(t, err)
+	require.Len(t, refunds, 2)
+
+	arg.Quantity = 1
+	_, err = store.RefundOrderTx(context.Background(), arg)
+	require.True(t, errors.Is(err, ErrInvalidOrderTransition))
+}
+
+func TestRefundOrderTxFulfilled(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	order := mockOrderTx(t, user, pokemon)
+
+	for _, status := range []string{OrderStatusPaid, OrderStatusFulfilled} {
+		_, err := store.UpdateOrderStatusTx(context.Background(), UpdateOrderStatusTxParams{
+			ID:     order.Order.ID,
+			Status: status,
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := store.RefundOrderTx(context.Background(), RefundOrderTxParams{
+		OrderID:    order.Order.ID,
+		Quantity:   order.Order.Quantity,
+		Reason:     util.RandomString(12),
+		RefundedBy: util.RandomUser(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusFulfilled, result.Refund.OrderStatus)
+	require.Equal(t, OrderStatusRefunded, result.Order.Status)
+	require.Equal(t, int64(0), result.Order.NetTotal)
+
+	// the refunded pokemon were handed back, the quantity goes back to the stock
+	product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock, product.PokeStock)
+}
+
+func TestRefundOrderTxPartialCancel(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemons := []PokeProduct{mockRandomData(t), mockRandomData(t)}
+	for _, pokemon := range pokemons {
+		mockAddCartItem(t, user, pokemon, 2)
+	}
+
+	checkout, err := store.CheckoutTx(context.Background(), CheckoutTxParams{UserID: user.ID})
+	require.NoError(t, err)
+
+	arg := RefundOrderTxParams{
+		OrderID:    checkout.Order.ID,
+		Quantity:   1,
+		Reason:     util.RandomString(12),
+		RefundedBy: util.RandomUser(),
+	}
+	_, err = store.RefundOrderTx(context.Background(), arg)
+	require.True(t, errors.Is(err, ErrRefundItemRequired))
+
+	arg.ItemID = checkout.Items[0].ID + checkout.Items[1].ID
+	_, err = store.RefundOrderTx(context.Background(), arg)
+	require.Equal(t, sql.ErrNoRows, err)
+
+	// the first line is given back, the order stays pending
+	arg.ItemID = checkout.Items[0].ID
+	arg.Quantity = 2
+	result, err := store.RefundOrderTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusPending, result.Order.Status)
+	require.Equal(t, OrderStatusPending, result.Refund.OrderStatus)
+	require.Equal(t, checkout.Items[1].TotalPrice, result.Order.NetTotal)
+
+	// cancelling the order only restocks the line left
+	cancelled, err := store.CancelOrderTx(context.Background(), CancelOrderParam{ID: checkout.Order.ID})
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusCancelled, cancelled.Order.Status)
+	require.Equal(t, int64(0), cancelled.Order.NetTotal)
+
+	for _, pokemon := range pokemons {
+		product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+		require.NoError(t, err)
+		require.Equal(t, pokemon.PokeStock, product.PokeStock)
+	}
+}
@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Reservation statuses, an active reservation is either converted into an order or released
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusConverted = "converted"
+	ReservationStatusReleased  = "released"
+)
+
+var (
+	// ErrReservationNotActive is returned when a reservation was already converted or released
+	ErrReservationNotActive = errors.New("reservation is not active")
+	// ErrReservationExpired is returned when converting a reservation past its expiry
+	ErrReservationExpired = errors.New("reservation has expired")
+)
+
+// ReserveStockTxParams contains input parameter of the reservation transaction
+type ReserveStockTxParams struct {
+	UserID    int64     `json:"user_id"`
+	ProductID int64     `json:"product_id"`
+	Quantity  int32     `json:"quantity"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ReservationTxResult struct {
+	Reservation StockReservation `json:"reservation"`
+	Product     PokeProduct      `json:"product"`
+}
+
+// ReserveStockTx holds stock of a product for the user until the reservation expires,
+// the quantity is moved from the available to the reserved stock
+func (store *SQLStore) ReserveStockTx(ctx context.Context, arg ReserveStockTxParams) (ReservationTxResult, error) {
+	var result ReservationTxResult
+
+	err := store.execTx(ctx, serializableTx, func(q *Queries) error {
+		product, err := q.GetPokemonDataForUpdate(ctx, arg.ProductID)
+		if err != nil {
+			return err
+		}
+
+		result.Product, err = q.ReservePokemonStockData(ctx, ReservePokemonStockDataParams{
+			ID:     product.ID,
+			Amount: int64(arg.Quantity),
+		})
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %s has %d left, %d requested", ErrInsufficientStock, product.PokeName, product.PokeStock, arg.Quantity)
+		}
+		if err != nil {
+			return err
+		}
+
+		result.Reservation, err = q.CreateStockReservation(ctx, CreateStockReservationParams{
+			UserID:    arg.UserID,
+			ProductID: arg.ProductID,
+			Quantity:  arg.Quantity,
+			ExpiresAt: arg.ExpiresAt,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ReservationTxParams identifies a reservation of the user
+type ReservationTxParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+// getActiveReservation locks the reservation of the user, reservations of other users are not found
+func getActiveReservation(ctx context.Context, q *Queries, arg ReservationTxParams) (StockReservation, error) {
+	reservation, err := q.GetStockReservationForUpdate(ctx, arg.ID)
+	if err != nil {
+		return reservation, err
+	}
+
+	if reservation.UserID != arg.UserID {
+		return reservation, sql.ErrNoRows
+	}
+
+	if reservation.Status != ReservationStatusActive {
+		return reservation, fmt.Errorf("%w: %s", ErrReservationNotActive, reservation.Status)
+	}
+
+	return reservation, nil
+}
+
+// releaseReservation gives the quantity of an active reservation back to the available stock
+func releaseReservation(ctx context.Context, q *Queries, reservation StockReservation) (ReservationTxResult, error) {
+	var result ReservationTxResult
+	var err error
+
+	result.Product, err = q.ReleasePokemonStockData(ctx, ReleasePokemonStockDataParams{
+		ID:     reservation.ProductID,
+		Amount: int64(reservation.Quantity),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Reservation, err = q.UpdateStockReservationStatus(ctx, UpdateStockReservationStatusParams{
+		ID:     reservation.ID,
+		Status: ReservationStatusReleased,
+	})
+	return result, err
+}
+
+// ReleaseReservationTx releases an active reservation of the user before it expires
+func (store *SQLStore) ReleaseReservationTx(ctx context.Context, arg ReservationTxParams) (ReservationTxResult, error) {
+	var result ReservationTxResult
+
+	err := store.execTx(ctx, serializableTx, func(q *Queries) error {
+		reservation, err := getActiveReservation(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		result, err = releaseReservation(ctx, q, reservation)
+		return err
+	})
+
+	return result, err
+}
+
+type ConvertReservationTxResult struct {
+	Order       PokeOrder        `json:"pokeorder"`
+	Reservation StockReservation `json:"reservation"`
+}
+
+// ConvertReservationTx turns an active reservation of the user into a pending order priced at the current price,
+// the reserved quantity is handed to the order without going back to the available stock
+func (store *SQLStore) ConvertReservationTx(ctx context.Context, arg ReservationTxParams) (ConvertReservationTxResult, error) {
+	var result ConvertReservationTxResult
+
+	err := store.execTx(ctx, serializableTx, func(q *Queries) error {
+		reservation, err := getActiveReservation(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		if !reservation.ExpiresAt.After(time.Now()) {
+			return ErrReservationExpired
+		}
+
+		product, err := q.GetPokemonDataForUpdate(ctx, reservation.ProductID)
+		if err != nil {
+			return err
+		}
+
+		result.Order, err = q.InsertPokemonOrderData(ctx, InsertPokemonOrderDataParams{
+			UserID:     reservation.UserID,
+			ProductID:  sql.NullInt64{Int64: reservation.ProductID, Valid: true},
+			Quantity:   reservation.Quantity,
+			TotalPrice: int64(reservation.Quantity) * product.PokePrice,
+			Currency:   product.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateOrderItem(ctx, CreateOrderItemParams{
+			OrderID:     result.Order.ID,
+			ProductID:   reservation.ProductID,
+			ProductName: product.PokeName,
+			Quantity:    reservation.Quantity,
+			UnitPrice:   product.PokePrice,
+			Currency:    product.Currency,
+			TotalPrice:  result.Order.TotalPrice,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ClaimReservedStockData(ctx, ClaimReservedStockDataParams{
+			ID:     reservation.ProductID,
+			Amount: int64(reservation.Quantity),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Reservation, err = q.UpdateStockReservationStatus(ctx, UpdateStockReservationStatusParams{
+			ID:      reservation.ID,
+			Status:  ReservationStatusConverted,
+			OrderID: sql.NullInt64{Int64: result.Order.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ReleaseExpiredReservationsTxParams contains input parameter of the expired reservations transaction
+type ReleaseExpiredReservationsTxParams struct {
+	Now   time.Time `json:"now"`
+	Limit int32     `json:"limit"`
+}
+
+type ReleaseExpiredReservationsTxResult struct {
+	Released []StockReservation `json:"released"`
+}
+
+// ReleaseExpiredReservationsTx releases a batch of the reservations expired at the given time,
+// reservations locked by another transaction are skipped and left to the next batch
+func (store *SQLStore) ReleaseExpiredReservationsTx(ctx context.Context, arg ReleaseExpiredReservationsTxParams) (ReleaseExpiredReservationsTxResult, error) {
+	var result ReleaseExpiredReservationsTxResult
+
+	err := store.execTx(ctx, serializableTx, func(q *Queries) error {
+		reservations, err := q.ListExpiredStockReservations(ctx, ListExpiredStockReservationsParams{
+			Now:        arg.Now,
+			LimitCount: arg.Limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Released = make([]StockReservation, len(reservations))
+		for i, reservation := range reservations {
+			released, err := releaseReservation(ctx, q, reservation)
+			if err != nil {
+				return err
+			}
+			result.Released[i] = released.Reservation
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mockReserveStockTx(t *testing.T, store Store, user User, pokemon PokeProduct, expiresAt time.Time) ReservationTxResult {
+	result, err := store.ReserveStockTx(context.Background(), ReserveStockTxParams{
+		UserID:    user.ID,
+		ProductID: pokemon.ID,
+		Quantity:  3,
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, user.ID, result.Reservation.UserID)
+	require.Equal(t, pokemon.ID, result.Reservation.ProductID)
+	require.Equal(t, int32(3), result.Reservation.Quantity)
+	require.Equal(t, ReservationStatusActive, result.Reservation.Status)
+	require.False(t, result.Reservation.OrderID.Valid)
+	require.WithinDuration(t, expiresAt, result.Reservation.ExpiresAt, time.Second)
+
+	require.Equal(t, pokemon.PokeStock-3, result.Product.PokeStock)
+	require.Equal(t, pokemon.ReservedStock+3, result.Product.ReservedStock)
+	require.Equal(t, pokemon.OnHandStock, result.Product.OnHandStock)
+	return result
+}
+
+func TestReserveStockTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	mockReserveStockTx(t, store, user, pokemon, time.Now().Add(time.Minute))
+
+	_, err := store.ReserveStockTx(context.Background(), ReserveStockTxParams{
+		UserID:    user.ID,
+		ProductID: pokemon.ID,
+		Quantity:  int32(pokemon.PokeStock),
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+
+	// the reserved stock is not available to orders
+	_, err = store.OrderTx(context.Background(), OrderTxParams{
+		UserID:    user.ID,
+		ProductID: pokemon.ID,
+		Quantity:  int32(pokemon.PokeStock) - 2,
+	})
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+}
+
+func TestConvertReservationTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	reserved := mockReserveStockTx(t, store, user, pokemon, time.Now().Add(time.Minute))
+
+	_, err := store.ConvertReservationTx(context.Background(), ReservationTxParams{
+		ID:     reserved.Reservation.ID,
+		UserID: user.ID + 1000000,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	result, err := store.ConvertReservationTx(context.Background(), ReservationTxParams{
+		ID:     reserved.Reservation.ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReservationStatusConverted, result.Reservation.Status)
+	require.Equal(t, result.Order.ID, result.Reservation.OrderID.Int64)
+	require.Equal(t, OrderStatusPending, result.Order.Status)
+	require.Equal(t, int32(3), result.Order.Quantity)
+	require.Equal(t, 3*pokemon.PokePrice, result.Order.TotalPrice)
+
+	product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock-3, product.PokeStock)
+	require.Equal(t, pokemon.ReservedStock, product.ReservedStock)
+	require.Equal(t, pokemon.OnHandStock-3, product.OnHandStock)
+
+	_, err = store.ConvertReservationTx(context.Background(), ReservationTxParams{
+		ID:     reserved.Reservation.ID,
+		UserID: user.ID,
+	})
+	require.True(t, errors.Is(err, ErrReservationNotActive))
+}
+
+func TestConvertExpiredReservationTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	reserved := mockReserveStockTx(t, store, user, pokemon, time.Now().Add(-time.Second))
+
+	_, err := store.ConvertReservationTx(context.Background(), ReservationTxParams{
+		ID:     reserved.Reservation.ID,
+		UserID: user.ID,
+	})
+	require.True(t, errors.Is(err, ErrReservationExpired))
+}
+
+func TestReleaseReservationTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	reserved := mockReserveStockTx(t, store, user, pokemon, time.Now().Add(time.Minute))
+
+	result, err := store.ReleaseReservationTx(context.Background(), ReservationTxParams{
+		ID:     reserved.Reservation.ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReservationStatusReleased, result.Reservation.Status)
+	require.Equal(t, pokemon.PokeStock, result.Product.PokeStock)
+	require.Equal(t, pokemon.ReservedStock, result.Product.ReservedStock)
+
+	_, err = store.ReleaseReservationTx(context.Background(), ReservationTxParams{
+		ID:     reserved.Reservation.ID,
+		UserID: user.ID,
+	})
+	require.True(t, errors.Is(err, ErrReservationNotActive))
+}
+
+func TestSweepReservations(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := mockCreateUserAccount(t)
+	pokemon := mockRandomData(t)
+	expired := mockReserveStockTx(t, store, user, pokemon, time.Now().Add(-time.Minute))
+	active := mockReserveStockTx(t, store, user, expired.Product, time.Now().Add(time.Hour))
+
+	released := SweepReservations(context.Background(), store, time.Now())
+	require.GreaterOrEqual(t, released, 1)
+
+	reservation, err := testQueries.GetStockReservation(context.Background(), expired.Reservation.ID)
+	require.NoError(t, err)
+	require.Equal(t, ReservationStatusReleased, reservation.Status)
+
+	reservation, err = testQueries.GetStockReservation(context.Background(), active.Reservation.ID)
+	require.NoError(t, err)
+	require.Equal(t, ReservationStatusActive, reservation.Status)
+
+	product, err := testQueries.GetPokemonData(context.Background(), pokemon.ID)
+	require.NoError(t, err)
+	require.Equal(t, pokemon.PokeStock-3, product.PokeStock)
+	require.Equal(t, pokemon.ReservedStock+3, product.ReservedStock)
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"expvar"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// DefaultTxMaxRetries is how many times a transaction is run again after a serialization failure or a deadlock
+	DefaultTxMaxRetries = 5
+	// DefaultTxRetryBackoff is the wait before the first retry, it doubles on every following one
+	DefaultTxRetryBackoff = 10 * time.Millisecond
+)
+
+// serializableTx runs the transaction as if it was alone on the database, conflicting ones are retried
+var serializableTx = &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+// txMetrics counts the outcome of the transactions, published on /debug/vars as "db_tx"
+//   - committed: transactions committed, whatever the number of attempts
+//   - failed: transactions given up on an error which is not worth a retry
+//   - retried: attempts run again after a serialization failure or a deadlock
+//   - retries_exhausted: transactions given up after the last retry
+var txMetrics = expvar.NewMap("db_tx")
+
+// isRetryableTxError reports whether the transaction failed only because of a concurrent one,
+// running it again from the start is then expected to succeed
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	switch pqErr.Code.Name() {
+	case "serialization_failure", "deadlock_detected":
+		return true
+	}
+	return false
+}
+
+// txBackoff is the wait before the given retry, the exponential backoff is jittered
+// so the transactions that conflicted do not collide again
+func txBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base << uint(attempt)
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	require.True(t, isRetryableTxError(&pq.Error{Code: "40001"}))
+	require.True(t, isRetryableTxError(&pq.Error{Code: "40P01"}))
+	require.True(t, isRetryableTxError(fmt.Errorf("tx err: %w, rb err: %v", &pq.Error{Code: "40001"}, sql.ErrTxDone)))
+
+	require.False(t, isRetryableTxError(&pq.Error{Code: "23505"}))
+	require.False(t, isRetryableTxError(sql.ErrNoRows))
+	require.False(t, isRetryableTxError(nil))
+}
+
+func TestTxBackoff(t *testing.T) {
+	base := 10 * time.Millisecond
+	for attempt := 0; attempt < 5; attempt++ {
+		backoff := base << uint(attempt)
+		for i := 0; i < 20; i++ {
+			wait := txBackoff(base, attempt)
+			require.GreaterOrEqual(t, int64(wait), int64(backoff/2))
+			require.LessOrEqual(t, int64(wait), int64(backoff))
+		}
+	}
+
+	require.Zero(t, txBackoff(0, 3))
+}
+
+func txMetric(name string) int64 {
+	if v, ok := txMetrics.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestExecTxRetry(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	store.maxTxRetries = 2
+	store.txRetryBackoff = time.Millisecond
+
+	serializationFailure := &pq.Error{Code: "40001"}
+
+	testCases := []struct {
+		name      string
+		failures  int
+		fnErr     error
+		attempts  int
+		checkErr  func(t *testing.T, err error)
+		metric    string
+		retriedBy int64
+	}{
+		{
+			name:      "RetriedThenCommitted",
+			failures:  2,
+			fnErr:     serializationFailure,
+			attempts:  3,
+			checkErr:  func(t *testing.T, err error) { require.NoError(t, err) },
+			metric:    "committed",
+			retriedBy: 2,
+		},
+		{
+			name:     "RetriesExhausted",
+			failures: 10,
+			fnErr:    serializationFailure,
+			attempts: 3,
+			checkErr: func(t *testing.T, err error) {
+				require.True(t, isRetryableTxError(err))
+			},
+			metric:    "retries_exhausted",
+			retriedBy: 2,
+		},
+		{
+			name:     "NotRetryable",
+			failures: 10,
+			fnErr:    ErrInsufficientStock,
+			attempts: 1,
+			checkErr: func(t *testing.T, err error) {
+				require.True(t, errors.Is(err, ErrInsufficientStock))
+			},
+			metric: "failed",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			retried := txMetric("retried")
+			outcome := txMetric(tc.metric)
+
+			attempts := 0
+			err := store.execTx(context.Background(), serializableTx, func(q *Queries) error {
+				attempts++
+				if attempts <= tc.failures {
+					return tc.fnErr
+				}
+				return nil
+			})
+
+			tc.checkErr(t, err)
+			require.Equal(t, tc.attempts, attempts)
+			require.Equal(t, retried+tc.retriedBy, txMetric("retried"))
+			require.Equal(t, outcome+1, txMetric(tc.metric))
+		})
+	}
+}
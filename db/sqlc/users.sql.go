@@ -5,6 +5,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createUserAccount = `-- name: CreateUserAccount :one
@@ -61,18 +62,70 @@ func (q *Queries) GetUserAccount(ctx context.Context, id int64) (User, error) {
 
 const listUserAccount = `-- name: ListUserAccount :many
 SELECT id, user_name, user_role, created_at FROM users
+WHERE $1::bigint IS NULL OR id > $1
 ORDER BY id
-LIMIT $1
-OFFSET $2
+LIMIT $2
+OFFSET $3
 `
 
 type ListUserAccountParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	AfterID sql.NullInt64 `json:"after_id"`
+	Limit   int32         `json:"limit"`
+	Offset  int32         `json:"offset"`
 }
 
+// a null after_id starts from the first user
 func (q *Queries) ListUserAccount(ctx context.Context, arg ListUserAccountParams) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, listUserAccount, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listUserAccount, arg.AfterID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserName,
+			&i.UserRole,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserAccountByName = `-- name: ListUserAccountByName :many
+SELECT id, user_name, user_role, created_at FROM users
+WHERE user_name = $1
+    AND ($2::bigint IS NULL OR id > $2)
+ORDER BY id
+LIMIT $3
+OFFSET $4
+`
+
+type ListUserAccountByNameParams struct {
+	UserName string        `json:"user_name"`
+	AfterID  sql.NullInt64 `json:"after_id"`
+	Limit    int32         `json:"limit"`
+	Offset   int32         `json:"offset"`
+}
+
+func (q *Queries) ListUserAccountByName(ctx context.Context, arg ListUserAccountByNameParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUserAccountByName,
+		arg.UserName,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
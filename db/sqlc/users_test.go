@@ -93,3 +93,22 @@ func TestListUserAccount(t *testing.T) {
 	}
 
 }
+
+func TestListUserAccountByName(t *testing.T) {
+	user1 := mockCreateUserAccount(t)
+	mockCreateUserAccount(t)
+
+	arg := ListUserAccountByNameParams{
+		UserName: user1.UserName,
+		Limit:    5,
+		Offset:   0,
+	}
+
+	users, err := testQueries.ListUserAccountByName(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+
+	for _, user := range users {
+		require.Equal(t, user1.UserName, user.UserName)
+	}
+}
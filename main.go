@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 
@@ -21,7 +22,11 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+	go db.RunReservationSweeper(context.Background(), store, config.ReservationSweepInterval)
+	go db.RunIdempotencyKeySweeper(context.Background(), store, config.IdempotencySweepInterval, config.IdempotencyKeyTTL)
+
+	revocations := db.NewRevocationStore(store)
+	server, err := api.NewServer(config, store, revocations)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
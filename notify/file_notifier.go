@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileNotifier appends messages as JSON lines into a file, meant for development and tests
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+type fileMessage struct {
+	Message
+	SentAt time.Time `json:"sent_at"`
+}
+
+// NewFileNotifier creates a new FileNotifier writing into path
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	if path == "" {
+		return nil, errors.New("file notifier requires a path")
+	}
+	return &FileNotifier{path: path}, nil
+}
+
+// Notify appends the message into the file
+func (notifier *FileNotifier) Notify(ctx context.Context, msg Message) error {
+	data, err := json.Marshal(fileMessage{Message: msg, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	file, err := os.OpenFile(notifier.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open notification file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
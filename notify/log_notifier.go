@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"os"
+)
+
+// LogNotifier writes messages into the service log, meant for development
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier creates a new LogNotifier writing on stderr
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{
+		logger: log.New(os.Stderr, "[notify] ", log.LstdFlags),
+	}
+}
+
+// Notify writes the message into the log
+func (notifier *LogNotifier) Notify(ctx context.Context, msg Message) error {
+	notifier.logger.Printf("to: %s, subject: %s, body: %s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
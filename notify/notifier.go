@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	TypeLog  = "log"
+	TypeFile = "file"
+)
+
+// Message is a notification addressed to the owner of an account
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier is an interface for delivering messages to account owners
+type Notifier interface {
+	// Notify delivers the message
+	Notify(ctx context.Context, msg Message) error
+}
+
+// New creates the notifier of the given type, the log notifier is used when empty
+func New(notifierType string, path string) (Notifier, error) {
+	switch notifierType {
+	case "", TypeLog:
+		return NewLogNotifier(), nil
+	case TypeFile:
+		return NewFileNotifier(path)
+	default:
+		return nil, fmt.Errorf("unsupported notifier type %s", notifierType)
+	}
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	notifier, err := New("", "")
+	require.NoError(t, err)
+	require.IsType(t, &LogNotifier{}, notifier)
+
+	err = notifier.Notify(context.Background(), Message{To: "ash", Subject: "hello", Body: "world"})
+	require.NoError(t, err)
+
+	_, err = New(TypeFile, "")
+	require.Error(t, err)
+
+	_, err = New("smtp", "")
+	require.Error(t, err)
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+
+	notifier, err := New(TypeFile, path)
+	require.NoError(t, err)
+
+	messages := []Message{
+		{To: "ash", Subject: "first", Body: "one"},
+		{To: "misty", Subject: "second", Body: "two"},
+	}
+	for _, msg := range messages {
+		err = notifier.Notify(context.Background(), msg)
+		require.NoError(t, err)
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var written []fileMessage
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var msg fileMessage
+		err = json.Unmarshal(scanner.Bytes(), &msg)
+		require.NoError(t, err)
+		written = append(written, msg)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, written, len(messages))
+	for i, msg := range written {
+		require.Equal(t, messages[i], msg.Message)
+		require.NotZero(t, msg.SentAt)
+	}
+}
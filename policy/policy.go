@@ -0,0 +1,116 @@
+package policy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// AnyRole grants a route to every authenticated account, even without a selected user
+const AnyRole = "*"
+
+//go:embed default.json
+var defaultConfig []byte
+
+// Config is the role to permitted routes matrix, a route is written as "METHOD /path"
+// with the path pattern used on the router, e.g. "GET /order/:id"
+type Config struct {
+	Roles map[string][]string `json:"roles"`
+}
+
+// Policy answers whether a role is permitted to call a route
+type Policy struct {
+	roles map[string]map[string]bool
+}
+
+// New creates a new Policy from the given matrix
+func New(config Config) (*Policy, error) {
+	policy := &Policy{
+		roles: make(map[string]map[string]bool, len(config.Roles)),
+	}
+
+	for role, routes := range config.Roles {
+		if role == "" {
+			return nil, errors.New("empty role name")
+		}
+
+		permitted := make(map[string]bool, len(routes))
+		for _, route := range routes {
+			method, path, err := parseRoute(route)
+			if err != nil {
+				return nil, fmt.Errorf("role %s: %w", role, err)
+			}
+			permitted[method+" "+path] = true
+		}
+		policy.roles[role] = permitted
+	}
+
+	return policy, nil
+}
+
+// Default creates the Policy shipped with the service
+func Default() (*Policy, error) {
+	return parse(defaultConfig)
+}
+
+// Load reads a Policy from a JSON file, the default one is used when path is empty
+func Load(path string) (*Policy, error) {
+	if path == "" {
+		return Default()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy file: %w", err)
+	}
+
+	return parse(data)
+}
+
+// Allowed checks if the role is permitted to call the route
+func (policy *Policy) Allowed(role, method, path string) bool {
+	route := strings.ToUpper(method) + " " + path
+	if policy.roles[AnyRole][route] {
+		return true
+	}
+
+	if role == "" {
+		return false
+	}
+
+	return policy.roles[role][route]
+}
+
+func parse(data []byte) (*Policy, error) {
+	var config Config
+	err := json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse policy: %w", err)
+	}
+
+	return New(config)
+}
+
+func parseRoute(route string) (string, string, error) {
+	fields := strings.Fields(route)
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("invalid route %q: must be \"METHOD /path\"", route)
+	}
+
+	method := strings.ToUpper(fields[0])
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return "", "", fmt.Errorf("invalid route %q: unsupported method %s", route, fields[0])
+	}
+
+	if !strings.HasPrefix(fields[1], "/") {
+		return "", "", fmt.Errorf("invalid route %q: path must start with /", route)
+	}
+
+	return method, fields[1], nil
+}
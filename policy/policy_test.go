@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	policy, err := Default()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		role    string
+		method  string
+		path    string
+		allowed bool
+	}{
+		{name: "GruntCreateOrder", role: "GRUNT", method: http.MethodPost, path: "/order", allowed: true},
+		{name: "GruntCancelOrder", role: "GRUNT", method: http.MethodDelete, path: "/order/:id", allowed: true},
+		{name: "GruntListOrder", role: "GRUNT", method: http.MethodGet, path: "/order", allowed: false},
+		{name: "GruntUpdateUser", role: "GRUNT", method: http.MethodPut, path: "/user/:id", allowed: false},
+		{name: "GruntListUser", role: "GRUNT", method: http.MethodGet, path: "/user", allowed: true},
+		{name: "GruntDeleteUser", role: "GRUNT", method: http.MethodDelete, path: "/user/:id", allowed: false},
+		{name: "LeadListOrder", role: "LEAD", method: http.MethodGet, path: "/order", allowed: true},
+		{name: "LeadGetOrder", role: "LEAD", method: http.MethodGet, path: "/order/:id", allowed: true},
+		{name: "AdminGetOrderItems", role: "ADMIN", method: http.MethodGet, path: "/order/:id/items", allowed: true},
+		{name: "LeadCreateOrder", role: "LEAD", method: http.MethodPost, path: "/order", allowed: false},
+		{name: "LeadUpdateUser", role: "LEAD", method: http.MethodPut, path: "/user/:id", allowed: false},
+		{name: "AdminUpdateUser", role: "ADMIN", method: http.MethodPut, path: "/user/:id", allowed: true},
+		{name: "AdminDeleteUser", role: "ADMIN", method: http.MethodDelete, path: "/user/:id", allowed: true},
+		{name: "AdminDisableAccount", role: "ADMIN", method: http.MethodPost, path: "/account/disable", allowed: true},
+		{name: "LeadDisableAccount", role: "LEAD", method: http.MethodPost, path: "/account/disable", allowed: false},
+		{name: "LeadRevokeSessions", role: "LEAD", method: http.MethodPost, path: "/account/sessions/revoke", allowed: false},
+		{name: "AdminRevokeSessions", role: "ADMIN", method: http.MethodPost, path: "/account/sessions/revoke", allowed: true},
+		{name: "AnyRoleCreateUser", role: "GRUNT", method: http.MethodPost, path: "/user", allowed: true},
+		{name: "NoUserSelected", role: "", method: http.MethodPost, path: "/user", allowed: true},
+		{name: "NoUserSelectedOrder", role: "", method: http.MethodPost, path: "/order", allowed: false},
+		{name: "UnknownRole", role: "BOSS", method: http.MethodGet, path: "/order", allowed: false},
+		{name: "UnknownRoute", role: "LEAD", method: http.MethodGet, path: "/unknown", allowed: false},
+		{name: "LowerCaseMethod", role: "LEAD", method: "get", path: "/order", allowed: true},
+		{name: "LeadCreatePromotion", role: "LEAD", method: http.MethodPost, path: "/promotion", allowed: true},
+		{name: "GruntCreatePromotion", role: "GRUNT", method: http.MethodPost, path: "/promotion", allowed: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.allowed, policy.Allowed(tc.role, tc.method, tc.path))
+		})
+	}
+}
+
+func TestNewPolicyInvalidRoute(t *testing.T) {
+	testCases := []struct {
+		name  string
+		route string
+	}{
+		{name: "MissingMethod", route: "/order"},
+		{name: "UnsupportedMethod", route: "FETCH /order"},
+		{name: "RelativePath", route: "GET order"},
+		{name: "ExtraField", route: "GET /order now"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := New(Config{Roles: map[string][]string{"LEAD": {tc.route}}})
+			require.Error(t, err)
+			require.Nil(t, policy)
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	err := ioutil.WriteFile(path, []byte(`{"roles": {"LEAD": ["get /order"]}}`), 0600)
+	require.NoError(t, err)
+
+	policy, err := Load(path)
+	require.NoError(t, err)
+	require.True(t, policy.Allowed("LEAD", http.MethodGet, "/order"))
+	require.False(t, policy.Allowed("LEAD", http.MethodPost, "/user"))
+
+	policy, err = Load("")
+	require.NoError(t, err)
+	require.True(t, policy.Allowed("LEAD", http.MethodPost, "/user"))
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const minSecretKeySize = 32
+
+// JWTMaker is a JSON Web Token maker
+type JWTMaker struct {
+	method     jwt.SigningMethod
+	signingKey interface{}
+	verifyKey  interface{}
+}
+
+// NewJWTMaker creates a new JWTMaker signing with HS256 and a shared secret key
+func NewJWTMaker(secretKey string) (Maker, error) {
+	if len(secretKey) < minSecretKeySize {
+		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+	}
+
+	maker := &JWTMaker{
+		method:     jwt.SigningMethodHS256,
+		signingKey: []byte(secretKey),
+		verifyKey:  []byte(secretKey),
+	}
+
+	return maker, nil
+}
+
+// NewAsymmetricJWTMaker creates a new JWTMaker signing with RS256 or EdDSA from a PEM encoded private key
+func NewAsymmetricJWTMaker(algorithm string, privateKeyPEM []byte) (Maker, error) {
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse RSA private key: %w", err)
+		}
+
+		maker := &JWTMaker{
+			method:     jwt.SigningMethodRS256,
+			signingKey: privateKey,
+			verifyKey:  &privateKey.PublicKey,
+		}
+		return maker, nil
+	case jwt.SigningMethodEdDSA.Alg():
+		key, err := jwt.ParseEdPrivateKeyFromPEM(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse Ed25519 private key: %w", err)
+		}
+
+		privateKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("invalid Ed25519 private key")
+		}
+
+		maker := &JWTMaker{
+			method:     jwt.SigningMethodEdDSA,
+			signingKey: privateKey,
+			verifyKey:  privateKey.Public(),
+		}
+		return maker, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %s", algorithm)
+	}
+}
+
+// CreateToken creates a new token for a specific username and duration
+func (maker *JWTMaker) CreateToken(username string, userID int64, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, userID, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.signToken(payload)
+}
+
+// CreateRefreshToken creates a new refresh token for a specific username and duration
+func (maker *JWTMaker) CreateRefreshToken(username string, userID int64, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewRefreshPayload(username, userID, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.signToken(payload)
+}
+
+// CreateMfaToken creates a new mfa token for a username waiting for its second factor
+func (maker *JWTMaker) CreateMfaToken(username string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewMfaPayload(username, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.signToken(payload)
+}
+
+// signToken signs the payload into a token
+func (maker *JWTMaker) signToken(payload *Payload) (string, *Payload, error) {
+	jwtToken := jwt.NewWithClaims(maker.method, payload)
+	token, err := jwtToken.SignedString(maker.signingKey)
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != maker.method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		return maker.verifyKey, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,76 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// DefaultKeyID is the ID of the key loaded from TOKEN_SYMETRIC_KEY when TOKEN_KEY_ID is empty,
+// and the legacy key of a key file that does not name one
+const DefaultKeyID = "default"
+
+// SymmetricKey is one of the keys in a keyring
+type SymmetricKey struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	RetiredAt time.Time `json:"retired_at"`
+}
+
+// Retired checks if the key no longer verify tokens at the given time
+func (key SymmetricKey) Retired(now time.Time) bool {
+	return !key.RetiredAt.IsZero() && !now.Before(key.RetiredAt)
+}
+
+// Keyring holds the key used to sign new tokens and the older keys that still verify,
+// the tokens issued without a key footer before the keyring existed are verified with the legacy key
+type Keyring struct {
+	ActiveKeyID string         `json:"active_key_id"`
+	LegacyKeyID string         `json:"legacy_key_id"`
+	Keys        []SymmetricKey `json:"keys"`
+}
+
+// KeyInfo describes a key without exposing its secret
+type KeyInfo struct {
+	ID        string     `json:"id"`
+	Active    bool       `json:"active"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+// KeyReporter is implemented by makers that sign tokens with identified keys
+type KeyReporter interface {
+	// Keys returns the keys in use, without their secret
+	Keys() []KeyInfo
+}
+
+// NewKeyring creates a keyring holding a single active key, which is also the legacy key
+// since the tokens issued without a key footer were encrypted with the same TOKEN_SYMETRIC_KEY
+func NewKeyring(keyID string, symmetricKey string) Keyring {
+	if keyID == "" {
+		keyID = DefaultKeyID
+	}
+
+	return Keyring{
+		ActiveKeyID: keyID,
+		LegacyKeyID: keyID,
+		Keys:        []SymmetricKey{{ID: keyID, Key: symmetricKey}},
+	}
+}
+
+// LoadKeyring reads a keyring from a JSON key file
+func LoadKeyring(path string) (Keyring, error) {
+	var keyring Keyring
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return keyring, fmt.Errorf("cannot read key file: %w", err)
+	}
+
+	err = json.Unmarshal(data, &keyring)
+	if err != nil {
+		return keyring, fmt.Errorf("cannot parse key file: %w", err)
+	}
+
+	return keyring, nil
+}
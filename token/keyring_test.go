@@ -0,0 +1,44 @@
+package token
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKeyring(t *testing.T) {
+	key1 := util.RandomString(32)
+	key2 := util.RandomString(32)
+
+	keyFile := `{
+		"active_key_id": "2026-10",
+		"keys": [
+			{"id": "2026-10", "key": "` + key1 + `"},
+			{"id": "2026-09", "key": "` + key2 + `", "retired_at": "2099-01-01T00:00:00Z"}
+		]
+	}`
+
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	err := ioutil.WriteFile(path, []byte(keyFile), 0600)
+	require.NoError(t, err)
+
+	keyring, err := LoadKeyring(path)
+	require.NoError(t, err)
+	require.Equal(t, "2026-10", keyring.ActiveKeyID)
+	require.Len(t, keyring.Keys, 2)
+	require.Equal(t, key1, keyring.Keys[0].Key)
+	require.True(t, keyring.Keys[0].RetiredAt.IsZero())
+	require.Equal(t, time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC), keyring.Keys[1].RetiredAt)
+
+	_, err = NewPasetoKeyringMaker(keyring)
+	require.NoError(t, err)
+}
+
+func TestLoadKeyringMissingFile(t *testing.T) {
+	_, err := LoadKeyring(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
@@ -6,8 +6,15 @@ import (
 
 // Maker is an interface for managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific username and duration
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+	// CreateToken creates a new token for a specific username and duration,
+	// userID and role are the user the session acts as, empty when none is selected
+	CreateToken(username string, userID int64, role string, duration time.Duration) (string, *Payload, error)
+
+	// CreateRefreshToken creates a new refresh token, it is only accepted to renew the access token of its session
+	CreateRefreshToken(username string, userID int64, role string, duration time.Duration) (string, *Payload, error)
+
+	// CreateMfaToken creates a new mfa token, it is only accepted to finish the login with the second factor
+	CreateMfaToken(username string, duration time.Duration) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string) (*Payload, error)
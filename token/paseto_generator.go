@@ -2,48 +2,124 @@ package token
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/aead/chacha20poly1305"
 	"github.com/o1egl/paseto"
 )
 
+// pasetoFooter is the plain footer of the token, telling which key encrypted it
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
 // PasetoMaker is a PASETO token maker
 type PasetoMaker struct {
-	paseto       *paseto.V2
-	symmetricKey []byte
+	paseto      *paseto.V2
+	activeKeyID string
+	legacyKeyID string
+	keys        map[string]SymmetricKey
 }
 
 // NewPasetoMaker creates a new PasetoMaker
 func NewPasetoMaker(symmetricKey string) (Maker, error) {
-	if len(symmetricKey) != chacha20poly1305.KeySize {
-		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
-	}
+	return NewPasetoKeyringMaker(NewKeyring(DefaultKeyID, symmetricKey))
+}
 
+// NewPasetoKeyringMaker creates a new PasetoMaker signing with the active key of the keyring
+func NewPasetoKeyringMaker(keyring Keyring) (Maker, error) {
 	maker := &PasetoMaker{
-		paseto:       paseto.NewV2(),
-		symmetricKey: []byte(symmetricKey),
+		paseto:      paseto.NewV2(),
+		activeKeyID: keyring.ActiveKeyID,
+		legacyKeyID: keyring.LegacyKeyID,
+		keys:        make(map[string]SymmetricKey, len(keyring.Keys)),
+	}
+
+	for _, key := range keyring.Keys {
+		if len(key.Key) != chacha20poly1305.KeySize {
+			return nil, fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
+		}
+		if _, ok := maker.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		maker.keys[key.ID] = key
+	}
+
+	if maker.legacyKeyID == "" {
+		maker.legacyKeyID = DefaultKeyID
+	}
+
+	activeKey, ok := maker.keys[keyring.ActiveKeyID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", keyring.ActiveKeyID)
+	}
+	if activeKey.Retired(time.Now()) {
+		return nil, fmt.Errorf("active key %q is already retired", keyring.ActiveKeyID)
 	}
 
 	return maker, nil
 }
 
 // CreateToken creates a new token for a specific username and duration
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateToken(username string, userID int64, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, userID, role, duration)
 	if err != nil {
 		return "", payload, err
 	}
 
-	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	return maker.signToken(payload)
+}
+
+// CreateRefreshToken creates a new refresh token for a specific username and duration
+func (maker *PasetoMaker) CreateRefreshToken(username string, userID int64, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewRefreshPayload(username, userID, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.signToken(payload)
+}
+
+// CreateMfaToken creates a new mfa token for a username waiting for its second factor
+func (maker *PasetoMaker) CreateMfaToken(username string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewMfaPayload(username, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.signToken(payload)
+}
+
+// signToken signs the payload into a token
+func (maker *PasetoMaker) signToken(payload *Payload) (string, *Payload, error) {
+	activeKey := maker.keys[maker.activeKeyID]
+	footer := pasetoFooter{KeyID: activeKey.ID}
+
+	token, err := maker.paseto.Encrypt([]byte(activeKey.Key), payload, footer)
 	return token, payload, err
 }
 
 // VerifyToken checks if the token is valid or not
 func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+	footer := pasetoFooter{}
+	err := paseto.ParseFooter(token, &footer)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if footer.KeyID == "" {
+		footer.KeyID = maker.legacyKeyID
+	}
+
+	key, ok := maker.keys[footer.KeyID]
+	if !ok || key.Retired(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
 	payload := &Payload{}
 
-	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
+	err = maker.paseto.Decrypt(token, []byte(key.Key), payload, nil)
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
@@ -55,3 +131,30 @@ func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 
 	return payload, nil
 }
+
+// Keys returns the keys of the keyring that still verify tokens, without their secret
+func (maker *PasetoMaker) Keys() []KeyInfo {
+	now := time.Now()
+	infos := make([]KeyInfo, 0, len(maker.keys))
+	for _, key := range maker.keys {
+		if key.Retired(now) {
+			continue
+		}
+
+		info := KeyInfo{
+			ID:     key.ID,
+			Active: key.ID == maker.activeKeyID,
+		}
+		if !key.RetiredAt.IsZero() {
+			retiredAt := key.RetiredAt
+			info.RetiredAt = &retiredAt
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+
+	return infos
+}
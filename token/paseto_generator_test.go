@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/o1egl/paseto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,12 +14,14 @@ func TestPasetoMaker(t *testing.T) {
 	require.NoError(t, err)
 
 	username := util.RandomUser()
+	userID := util.RandomInt(1, 200)
+	role := "GRUNT"
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, duration)
+	token, payload, err := maker.CreateToken(username, userID, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -29,15 +32,49 @@ func TestPasetoMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, userID, payload.UserID)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, AccessToken, payload.Kind)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
 
+func TestPasetoMakerRefreshToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateRefreshToken(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.Equal(t, RefreshToken, payload.Kind)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, RefreshToken, payload.Kind)
+}
+
+func TestPasetoMakerMfaToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomUser()
+	token, payload, err := maker.CreateMfaToken(username, time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.Equal(t, MfaToken, payload.Kind)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, MfaToken, payload.Kind)
+	require.Equal(t, username, payload.Username)
+	require.Empty(t, payload.Role)
+}
+
 func TestExpiredPasetoToken(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomUser(), -time.Minute)
+	token, payload, err := maker.CreateToken(util.RandomUser(), 0, "", -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -47,3 +84,152 @@ func TestExpiredPasetoToken(t *testing.T) {
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestPasetoMakerKeyRotation(t *testing.T) {
+	oldKeyring := NewKeyring("old", util.RandomString(32))
+	oldMaker, err := NewPasetoKeyringMaker(oldKeyring)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+
+	newKeyring := Keyring{
+		ActiveKeyID: "new",
+		Keys: []SymmetricKey{
+			{ID: "new", Key: util.RandomString(32)},
+			{ID: "old", Key: oldKeyring.Keys[0].Key, RetiredAt: time.Now().Add(time.Hour)},
+		},
+	}
+	newMaker, err := NewPasetoKeyringMaker(newKeyring)
+	require.NoError(t, err)
+
+	// token signed before the rotation is still valid until the old key retires
+	payload, err := newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	newToken, _, err := newMaker.CreateToken(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+
+	footer := pasetoFooter{}
+	err = paseto.ParseFooter(newToken, &footer)
+	require.NoError(t, err)
+	require.Equal(t, "new", footer.KeyID)
+
+	// the old maker doesn't know the new key
+	payload, err = oldMaker.VerifyToken(newToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+
+	keys := newMaker.(KeyReporter).Keys()
+	require.Len(t, keys, 2)
+	require.Equal(t, "new", keys[0].ID)
+	require.True(t, keys[0].Active)
+	require.Equal(t, "old", keys[1].ID)
+	require.False(t, keys[1].Active)
+	require.NotNil(t, keys[1].RetiredAt)
+}
+
+func TestPasetoMakerRetiredKey(t *testing.T) {
+	oldKeyring := NewKeyring("old", util.RandomString(32))
+	oldMaker, err := NewPasetoKeyringMaker(oldKeyring)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+
+	newMaker, err := NewPasetoKeyringMaker(Keyring{
+		ActiveKeyID: "new",
+		Keys: []SymmetricKey{
+			{ID: "new", Key: util.RandomString(32)},
+			{ID: "old", Key: oldKeyring.Keys[0].Key, RetiredAt: time.Now().Add(-time.Minute)},
+		},
+	})
+	require.NoError(t, err)
+
+	payload, err := newMaker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+
+	keys := newMaker.(KeyReporter).Keys()
+	require.Len(t, keys, 1)
+	require.Equal(t, "new", keys[0].ID)
+}
+
+func TestPasetoMakerInvalidKeyring(t *testing.T) {
+	testCases := []struct {
+		name    string
+		keyring Keyring
+	}{
+		{
+			name:    "InvalidKeySize",
+			keyring: NewKeyring("k1", util.RandomString(16)),
+		},
+		{
+			name: "MissingActiveKey",
+			keyring: Keyring{
+				ActiveKeyID: "missing",
+				Keys:        []SymmetricKey{{ID: "k1", Key: util.RandomString(32)}},
+			},
+		},
+		{
+			name: "RetiredActiveKey",
+			keyring: Keyring{
+				ActiveKeyID: "k1",
+				Keys:        []SymmetricKey{{ID: "k1", Key: util.RandomString(32), RetiredAt: time.Now().Add(-time.Minute)}},
+			},
+		},
+		{
+			name: "DuplicateKeyID",
+			keyring: Keyring{
+				ActiveKeyID: "k1",
+				Keys: []SymmetricKey{
+					{ID: "k1", Key: util.RandomString(32)},
+					{ID: "k1", Key: util.RandomString(32)},
+				},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewPasetoKeyringMaker(tc.keyring)
+			require.Error(t, err)
+			require.Nil(t, maker)
+		})
+	}
+}
+
+func TestPasetoMakerLegacyToken(t *testing.T) {
+	symmetricKey := util.RandomString(32)
+	payload, err := NewPayload(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+
+	// a token issued before the keyring has no key footer
+	legacyToken, err := paseto.NewV2().Encrypt([]byte(symmetricKey), payload, nil)
+	require.NoError(t, err)
+
+	maker, err := NewPasetoKeyringMaker(NewKeyring("2026-10", symmetricKey))
+	require.NoError(t, err)
+
+	verified, err := maker.VerifyToken(legacyToken)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+
+	// a key file names the key the legacy tokens were encrypted with
+	maker, err = NewPasetoKeyringMaker(Keyring{
+		ActiveKeyID: "2026-11",
+		LegacyKeyID: "2026-10",
+		Keys: []SymmetricKey{
+			{ID: "2026-11", Key: util.RandomString(32)},
+			{ID: "2026-10", Key: symmetricKey, RetiredAt: time.Now().Add(time.Hour)},
+		},
+	})
+	require.NoError(t, err)
+
+	verified, err = maker.VerifyToken(legacyToken)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+}
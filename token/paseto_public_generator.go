@@ -0,0 +1,157 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/o1egl/paseto"
+)
+
+// PublicKeyInfo describes a public key other services use to verify tokens offline
+type PublicKeyInfo struct {
+	KeyID     string `json:"kid"`
+	Version   string `json:"version"`
+	Purpose   string `json:"purpose"`
+	PublicKey string `json:"public_key"`
+	Paserk    string `json:"paserk"`
+}
+
+// PublicKeyPublisher is implemented by makers whose tokens can be verified with a public key
+type PublicKeyPublisher interface {
+	// PublicKeys returns the public keys that verify the tokens
+	PublicKeys() []PublicKeyInfo
+}
+
+// PasetoPublicMaker is a PASETO v2.public token maker signing with Ed25519
+type PasetoPublicMaker struct {
+	paseto     *paseto.V2
+	keyID      string
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewPasetoPublicMaker creates a new PasetoPublicMaker
+func NewPasetoPublicMaker(keyID string, privateKey ed25519.PrivateKey) (Maker, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d bytes", ed25519.PrivateKeySize)
+	}
+
+	if keyID == "" {
+		keyID = DefaultKeyID
+	}
+
+	maker := &PasetoPublicMaker{
+		paseto:     paseto.NewV2(),
+		keyID:      keyID,
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}
+
+	return maker, nil
+}
+
+// ParseEd25519PrivateKeyPEM parses a PKCS8 PEM encoded Ed25519 private key
+func ParseEd25519PrivateKeyPEM(privateKeyPEM []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("invalid PEM encoded private key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key: %w", err)
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an Ed25519 key")
+	}
+
+	return privateKey, nil
+}
+
+// CreateToken creates a new token for a specific username and duration
+func (maker *PasetoPublicMaker) CreateToken(username string, userID int64, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, userID, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.signToken(payload)
+}
+
+// CreateRefreshToken creates a new refresh token for a specific username and duration
+func (maker *PasetoPublicMaker) CreateRefreshToken(username string, userID int64, role string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewRefreshPayload(username, userID, role, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.signToken(payload)
+}
+
+// CreateMfaToken creates a new mfa token for a username waiting for its second factor
+func (maker *PasetoPublicMaker) CreateMfaToken(username string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewMfaPayload(username, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.signToken(payload)
+}
+
+// signToken signs the payload into a token
+func (maker *PasetoPublicMaker) signToken(payload *Payload) (string, *Payload, error) {
+	footer := pasetoFooter{KeyID: maker.keyID}
+
+	token, err := maker.paseto.Sign(maker.privateKey, payload, footer)
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	footer := pasetoFooter{}
+	err := paseto.ParseFooter(token, &footer)
+	if err != nil || footer.KeyID != maker.keyID {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+
+	err = maker.paseto.Verify(token, maker.publicKey, payload, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// Keys returns the key in use, without its secret
+func (maker *PasetoPublicMaker) Keys() []KeyInfo {
+	return []KeyInfo{{ID: maker.keyID, Active: true}}
+}
+
+// PublicKeys returns the public key that verify the tokens
+func (maker *PasetoPublicMaker) PublicKeys() []PublicKeyInfo {
+	encoded := base64.RawURLEncoding.EncodeToString(maker.publicKey)
+
+	return []PublicKeyInfo{
+		{
+			KeyID:     maker.keyID,
+			Version:   "v2",
+			Purpose:   "public",
+			PublicKey: encoded,
+			Paserk:    "k2.public." + encoded,
+		},
+	}
+}
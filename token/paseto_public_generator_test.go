@@ -0,0 +1,91 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/o1egl/paseto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPasetoPublicMaker(t *testing.T, keyID string) Maker {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyID, privateKey)
+	require.NoError(t, err)
+	return maker
+}
+
+func TestPasetoPublicMaker(t *testing.T) {
+	maker := newTestPasetoPublicMaker(t, "k1")
+
+	requireValidToken(t, maker)
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	maker := newTestPasetoPublicMaker(t, "k1")
+
+	token, payload, err := maker.CreateToken(util.RandomUser(), 0, "", -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicTokenOtherKey(t *testing.T) {
+	maker1 := newTestPasetoPublicMaker(t, "k1")
+	maker2 := newTestPasetoPublicMaker(t, "k1")
+
+	token, _, err := maker1.CreateToken(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker2.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicKeysVerifyOffline(t *testing.T) {
+	maker := newTestPasetoPublicMaker(t, "k1")
+	username := util.RandomUser()
+
+	token, _, err := maker.CreateToken(username, 0, "", time.Minute)
+	require.NoError(t, err)
+
+	keys := maker.(PublicKeyPublisher).PublicKeys()
+	require.Len(t, keys, 1)
+	require.Equal(t, "k1", keys[0].KeyID)
+	require.Equal(t, "k2.public."+keys[0].PublicKey, keys[0].Paserk)
+
+	// a third party only holding the published key is able to verify the token
+	publicKey, err := base64.RawURLEncoding.DecodeString(keys[0].PublicKey)
+	require.NoError(t, err)
+
+	payload := &Payload{}
+	err = paseto.NewV2().Verify(token, ed25519.PublicKey(publicKey), payload, nil)
+	require.NoError(t, err)
+	require.Equal(t, username, payload.Username)
+}
+
+func TestParseEd25519PrivateKeyPEM(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	parsed, err := ParseEd25519PrivateKeyPEM(encodePrivateKeyPEM(t, privateKey))
+	require.NoError(t, err)
+	require.Equal(t, privateKey, parsed)
+
+	_, err = ParseEd25519PrivateKeyPEM([]byte("not a pem"))
+	require.Error(t, err)
+
+	_, err = NewPasetoPublicMaker("k1", privateKey[:10])
+	require.Error(t, err)
+}
@@ -13,16 +13,41 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// Kinds of token, a refresh token only renews the access token of its session
+// and an mfa token is only exchanged with the second factor for a session
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+	MfaToken     = "mfa"
+)
+
 // Payload contains the payload data of the token
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	UserID    int64     `json:"user_id"`
+	Role      string    `json:"role"`
+	Kind      string    `json:"kind"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new access token payload with a specific username, selected user, role and duration
+func NewPayload(username string, userID int64, role string, duration time.Duration) (*Payload, error) {
+	return newPayload(username, userID, role, AccessToken, duration)
+}
+
+// NewRefreshPayload creates a new refresh token payload with a specific username, selected user, role and duration
+func NewRefreshPayload(username string, userID int64, role string, duration time.Duration) (*Payload, error) {
+	return newPayload(username, userID, role, RefreshToken, duration)
+}
+
+// NewMfaPayload creates a new mfa token payload for a username waiting for its second factor
+func NewMfaPayload(username string, duration time.Duration) (*Payload, error) {
+	return newPayload(username, 0, "", MfaToken, duration)
+}
+
+func newPayload(username string, userID int64, role string, kind string, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -31,6 +56,9 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		UserID:    userID,
+		Role:      role,
+		Kind:      kind,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -0,0 +1,83 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrRevokedToken is returned when a token was revoked before it expired
+var ErrRevokedToken = errors.New("token has been revoked")
+
+// RevocationStore keeps track of tokens that must be rejected before they expire
+type RevocationStore interface {
+	// RevokeToken revokes a single token until it expires
+	RevokeToken(ctx context.Context, tokenID uuid.UUID, username string, expiredAt time.Time) error
+
+	// RevokeAccount revokes every token of the username issued before the given time
+	RevokeAccount(ctx context.Context, username string, before time.Time) error
+
+	// IsRevoked checks if the token was revoked, either by itself or through its account
+	IsRevoked(ctx context.Context, tokenID uuid.UUID, username string, issuedAt time.Time) (bool, error)
+}
+
+// MemoryRevocationStore is an in-memory RevocationStore
+type MemoryRevocationStore struct {
+	mu       sync.RWMutex
+	tokens   map[uuid.UUID]time.Time
+	accounts map[string]time.Time
+}
+
+// NewMemoryRevocationStore creates a new MemoryRevocationStore
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens:   make(map[uuid.UUID]time.Time),
+		accounts: make(map[string]time.Time),
+	}
+}
+
+// RevokeToken revokes a single token until it expires
+func (store *MemoryRevocationStore) RevokeToken(ctx context.Context, tokenID uuid.UUID, username string, expiredAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range store.tokens {
+		if now.After(exp) {
+			delete(store.tokens, id)
+		}
+	}
+
+	store.tokens[tokenID] = expiredAt
+	return nil
+}
+
+// RevokeAccount revokes every token of the username issued before the given time
+func (store *MemoryRevocationStore) RevokeAccount(ctx context.Context, username string, before time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if current, ok := store.accounts[username]; !ok || before.After(current) {
+		store.accounts[username] = before
+	}
+	return nil
+}
+
+// IsRevoked checks if the token was revoked, either by itself or through its account
+func (store *MemoryRevocationStore) IsRevoked(ctx context.Context, tokenID uuid.UUID, username string, issuedAt time.Time) (bool, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	if _, ok := store.tokens[tokenID]; ok {
+		return true, nil
+	}
+
+	if before, ok := store.accounts[username]; ok && issuedAt.Before(before) {
+		return true, nil
+	}
+
+	return false, nil
+}
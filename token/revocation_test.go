@@ -0,0 +1,65 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gunhachi/poke-blackmarket/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRevocationStore(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	payload1, err := NewPayload(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+	payload2, err := NewPayload(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+
+	revoked, err := store.IsRevoked(ctx, payload1.ID, payload1.Username, payload1.IssuedAt)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	err = store.RevokeToken(ctx, payload1.ID, payload1.Username, payload1.ExpiredAt)
+	require.NoError(t, err)
+
+	revoked, err = store.IsRevoked(ctx, payload1.ID, payload1.Username, payload1.IssuedAt)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = store.IsRevoked(ctx, payload2.ID, payload2.Username, payload2.IssuedAt)
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestMemoryRevocationStoreAccount(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	oldPayload, err := NewPayload(util.RandomUser(), 0, "", time.Minute)
+	require.NoError(t, err)
+
+	err = store.RevokeAccount(ctx, oldPayload.Username, time.Now())
+	require.NoError(t, err)
+
+	newPayload, err := NewPayload(oldPayload.Username, 0, "", time.Minute)
+	require.NoError(t, err)
+
+	revoked, err := store.IsRevoked(ctx, oldPayload.ID, oldPayload.Username, oldPayload.IssuedAt)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = store.IsRevoked(ctx, newPayload.ID, newPayload.Username, newPayload.IssuedAt)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	// an older cutoff must not move the revocation back
+	err = store.RevokeAccount(ctx, oldPayload.Username, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	revoked, err = store.IsRevoked(ctx, oldPayload.ID, oldPayload.Username, oldPayload.IssuedAt)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	apiKeyLabel       = "pbm"
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+)
+
+// ErrInvalidApiKey is returned when the api key format is not valid
+var ErrInvalidApiKey = errors.New("api key is invalid")
+
+// GenerateApiKey returns a new random api key and its public prefix, formatted as pbm_<prefix>_<secret>
+func GenerateApiKey() (key string, prefix string, err error) {
+	prefix, err = RandomSecret(apiKeyPrefixBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	secret, err := RandomSecret(apiKeySecretBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key = fmt.Sprintf("%s_%s_%s", apiKeyLabel, prefix, secret)
+	return key, prefix, nil
+}
+
+// ApiKeyPrefix returns the public prefix of the api key
+func ApiKeyPrefix(key string) (string, error) {
+	fields := strings.Split(key, "_")
+	if len(fields) != 3 || fields[0] != apiKeyLabel || fields[1] == "" || fields[2] == "" {
+		return "", ErrInvalidApiKey
+	}
+	return fields[1], nil
+}
+
+// CheckApiKey checks if the provided api key match the hashed one
+func CheckApiKey(key string, hashedKey string) error {
+	if subtle.ConstantTimeCompare([]byte(HashSecret(key)), []byte(hashedKey)) != 1 {
+		return ErrInvalidApiKey
+	}
+	return nil
+}
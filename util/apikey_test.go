@@ -0,0 +1,39 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApiKey(t *testing.T) {
+	key1, prefix1, err := GenerateApiKey()
+	require.NoError(t, err)
+	require.NotEmpty(t, key1)
+	require.NotEmpty(t, prefix1)
+
+	prefix, err := ApiKeyPrefix(key1)
+	require.NoError(t, err)
+	require.Equal(t, prefix1, prefix)
+
+	hashedKey := HashSecret(key1)
+	require.NotEqual(t, key1, hashedKey)
+
+	err = CheckApiKey(key1, hashedKey)
+	require.NoError(t, err)
+
+	key2, prefix2, err := GenerateApiKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key1, key2)
+	require.NotEqual(t, prefix1, prefix2)
+
+	err = CheckApiKey(key2, hashedKey)
+	require.EqualError(t, err, ErrInvalidApiKey.Error())
+}
+
+func TestInvalidApiKeyPrefix(t *testing.T) {
+	for _, key := range []string{"", "pbm", "pbm_abc", "xyz_abc_def", "pbm__def", "pbm_abc_def_ghi"} {
+		_, err := ApiKeyPrefix(key)
+		require.EqualError(t, err, ErrInvalidApiKey.Error(), key)
+	}
+}
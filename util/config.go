@@ -9,11 +9,31 @@ import (
 // Config store all configuration
 // Value passed from viper
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymKey         string        `mapstructure:"TOKEN_SYMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver                 string        `mapstructure:"DB_DRIVER"`
+	DBSource                 string        `mapstructure:"DB_SOURCE"`
+	ServerAddress            string        `mapstructure:"SERVER_ADDRESS"`
+	TokenType                string        `mapstructure:"TOKEN_TYPE"`
+	TokenSymKey              string        `mapstructure:"TOKEN_SYMETRIC_KEY"`
+	TokenKeyID               string        `mapstructure:"TOKEN_KEY_ID"`
+	TokenKeyringPath         string        `mapstructure:"TOKEN_KEYRING_PATH"`
+	TokenAlgorithm           string        `mapstructure:"TOKEN_ALGORITHM"`
+	TokenPrivateKeyPath      string        `mapstructure:"TOKEN_PRIVATE_KEY_PATH"`
+	AccessTokenDuration      time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration     time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	PolicyPath               string        `mapstructure:"POLICY_PATH"`
+	AdminUsername            string        `mapstructure:"ADMIN_USERNAME"`
+	LoginMaxAttempts         int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginLockoutDuration     time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	PasswordHasher           string        `mapstructure:"PASSWORD_HASHER"`
+	PasswordMinLength        int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	BreachedPasswordPath     string        `mapstructure:"BREACHED_PASSWORD_PATH"`
+	PasswordResetDuration    time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+	NotifierType             string        `mapstructure:"NOTIFIER_TYPE"`
+	NotifierPath             string        `mapstructure:"NOTIFIER_PATH"`
+	ReservationDuration      time.Duration `mapstructure:"RESERVATION_DURATION"`
+	ReservationSweepInterval time.Duration `mapstructure:"RESERVATION_SWEEP_INTERVAL"`
+	IdempotencyKeyTTL        time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	IdempotencySweepInterval time.Duration `mapstructure:"IDEMPOTENCY_SWEEP_INTERVAL"`
 }
 
 // LoadConfig reads configuration from file or environment variables.
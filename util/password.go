@@ -1,21 +1,226 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword returns the bcrypt hash of the password
+const (
+	PasswordHasherArgon2id = "argon2id"
+	PasswordHasherBcrypt   = "bcrypt"
+)
+
+var (
+	// ErrMismatchedPassword is returned when the password does not match the hash
+	ErrMismatchedPassword = errors.New("password does not match")
+	// ErrUnsupportedPasswordHash is returned when the hash format is not known by any hasher
+	ErrUnsupportedPasswordHash = errors.New("unsupported password hash format")
+)
+
+// PasswordHasher hashes passwords into an encoded string carrying its own parameters
+type PasswordHasher interface {
+	// Hash returns the encoded hash of the password
+	Hash(password string) (string, error)
+
+	// NeedsRehash reports whether the hash was produced by a weaker algorithm or weaker parameters
+	NeedsRehash(hashedPassword string) bool
+}
+
+// DefaultPasswordHasher is used by HashPassword
+var DefaultPasswordHasher PasswordHasher = NewArgon2idHasher()
+
+// NewPasswordHasher returns the hasher with the given name, argon2id when empty
+func NewPasswordHasher(name string) (PasswordHasher, error) {
+	switch name {
+	case "", PasswordHasherArgon2id:
+		return NewArgon2idHasher(), nil
+	case PasswordHasherBcrypt:
+		return NewBcryptHasher(), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hasher %s", name)
+	}
+}
+
+// HashPassword returns the hash of the password using the default hasher
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return DefaultPasswordHasher.Hash(password)
+}
+
+// CheckPassword checks if the provided password is correct or not, whatever hasher produced the hash
+func CheckPassword(password string, hashedPassword string) error {
+	switch {
+	case strings.HasPrefix(hashedPassword, argon2idPrefix):
+		return checkArgon2idPassword(password, hashedPassword)
+	case isBcryptHash(hashedPassword):
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatchedPassword
+		}
+		return err
+	default:
+		return ErrUnsupportedPasswordHash
+	}
+}
+
+const argon2idPrefix = "$argon2id$"
+
+// Argon2idHasher hashes passwords with argon2id, encoded as $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>
+type Argon2idHasher struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// NewArgon2idHasher returns an argon2id hasher with the recommended parameters of RFC 9106
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Time:    3,
+		Memory:  64 * 1024,
+		Threads: 4,
+		KeyLen:  32,
+		SaltLen: 16,
+	}
+}
+
+// Hash returns the encoded argon2id hash of the password
+func (hasher *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, hasher.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, hasher.Time, hasher.Memory, hasher.Threads, hasher.KeyLen)
+	params := argon2idParams{
+		time:    hasher.Time,
+		memory:  hasher.Memory,
+		threads: hasher.Threads,
+		salt:    salt,
+		key:     key,
+	}
+	return params.encode(), nil
+}
+
+// NeedsRehash reports whether the hash is not argon2id or uses lower parameters than the hasher
+func (hasher *Argon2idHasher) NeedsRehash(hashedPassword string) bool {
+	params, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return params.time < hasher.Time ||
+		params.memory < hasher.Memory ||
+		params.threads < hasher.Threads ||
+		uint32(len(params.key)) < hasher.KeyLen ||
+		uint32(len(params.salt)) < hasher.SaltLen
+}
+
+type argon2idParams struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (params argon2idParams) encode() string {
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		params.memory,
+		params.time,
+		params.threads,
+		base64.RawStdEncoding.EncodeToString(params.salt),
+		base64.RawStdEncoding.EncodeToString(params.key),
+	)
+}
+
+func decodeArgon2id(hashedPassword string) (argon2idParams, error) {
+	var params argon2idParams
+
+	fields := strings.Split(hashedPassword, "$")
+	if len(fields) != 6 || fields[1] != "argon2id" {
+		return params, ErrUnsupportedPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(fields[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, ErrUnsupportedPasswordHash
+	}
+
+	_, err := fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads)
+	if err != nil {
+		return params, ErrUnsupportedPasswordHash
+	}
+
+	params.salt, err = base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return params, ErrUnsupportedPasswordHash
+	}
+
+	params.key, err = base64.RawStdEncoding.DecodeString(fields[5])
+	if err != nil || len(params.key) == 0 {
+		return params, ErrUnsupportedPasswordHash
+	}
+
+	return params, nil
+}
+
+func checkArgon2idPassword(password string, hashedPassword string) error {
+	params, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	key := argon2.IDKey([]byte(password), params.salt, params.time, params.memory, params.threads, uint32(len(params.key)))
+	if subtle.ConstantTimeCompare(key, params.key) != 1 {
+		return ErrMismatchedPassword
+	}
+	return nil
+}
+
+// BcryptHasher hashes passwords with bcrypt, kept to verify and migrate older hashes
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher returns a bcrypt hasher at the default cost
+func NewBcryptHasher() *BcryptHasher {
+	return &BcryptHasher{Cost: bcrypt.DefaultCost}
+}
+
+// Hash returns the bcrypt hash of the password
+func (hasher *BcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.Cost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 	return string(hashedPassword), nil
 }
 
-// CheckPassword checks if the provided password is correct or not
-func CheckPassword(password string, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// NeedsRehash reports whether the hash is bcrypt at a lower cost than the hasher or of an unknown format,
+// an argon2id hash is stronger than bcrypt and is never downgraded
+func (hasher *BcryptHasher) NeedsRehash(hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		return false
+	}
+	if !isBcryptHash(hashedPassword) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost < hasher.Cost
+}
+
+func isBcryptHash(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2a$") ||
+		strings.HasPrefix(hashedPassword, "$2b$") ||
+		strings.HasPrefix(hashedPassword, "$2y$")
 }
@@ -0,0 +1,72 @@
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultPasswordMinLength is used when no minimum length is configured
+const DefaultPasswordMinLength = 8
+
+// ErrBreachedPassword is returned when the password is in the breached password list
+var ErrBreachedPassword = errors.New("password was found in a data breach, choose another one")
+
+// PasswordPolicy validates new passwords
+type PasswordPolicy struct {
+	minLength int
+	breached  map[string]struct{}
+}
+
+// NewPasswordPolicy creates a policy with a minimum length and an optional breached password list,
+// the file holds one password per line, blank lines and lines starting with # are ignored
+func NewPasswordPolicy(minLength int, breachedPath string) (*PasswordPolicy, error) {
+	if minLength <= 0 {
+		minLength = DefaultPasswordMinLength
+	}
+
+	policy := &PasswordPolicy{
+		minLength: minLength,
+		breached:  map[string]struct{}{},
+	}
+
+	if breachedPath == "" {
+		return policy, nil
+	}
+
+	file, err := os.Open(breachedPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		policy.breached[line] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read breached password list: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Validate returns an error when the password does not satisfy the policy
+func (policy *PasswordPolicy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < policy.minLength {
+		return fmt.Errorf("password must be at least %d characters", policy.minLength)
+	}
+
+	if _, ok := policy.breached[password]; ok {
+		return ErrBreachedPassword
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	breachedPath := filepath.Join(dir, "breached.txt")
+	err = ioutil.WriteFile(breachedPath, []byte("# common passwords\npassword123\n\nqwertyuiop\n"), 0600)
+	require.NoError(t, err)
+
+	policy, err := NewPasswordPolicy(10, breachedPath)
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Validate(RandomString(10)))
+	require.EqualError(t, policy.Validate("short"), "password must be at least 10 characters")
+	require.EqualError(t, policy.Validate("password123"), ErrBreachedPassword.Error())
+	require.EqualError(t, policy.Validate("qwertyuiop"), ErrBreachedPassword.Error())
+
+	policy, err = NewPasswordPolicy(0, "")
+	require.NoError(t, err)
+	require.Error(t, policy.Validate(RandomString(DefaultPasswordMinLength-1)))
+	require.NoError(t, policy.Validate(RandomString(DefaultPasswordMinLength)))
+
+	_, err = NewPasswordPolicy(8, filepath.Join(dir, "missing.txt"))
+	require.Error(t, err)
+}
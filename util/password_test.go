@@ -1,6 +1,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,16 +14,78 @@ func TestPassword(t *testing.T) {
 	hashedPassword1, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword1)
+	require.True(t, strings.HasPrefix(hashedPassword1, "$argon2id$v=19$m=65536,t=3,p=4$"))
 
 	err = CheckPassword(password, hashedPassword1)
 	require.NoError(t, err)
 
 	wrongPassword := RandomString(6)
 	err = CheckPassword(wrongPassword, hashedPassword1)
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	require.EqualError(t, err, ErrMismatchedPassword.Error())
 
 	hashedPassword2, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword2)
 	require.NotEqual(t, hashedPassword1, hashedPassword2)
 }
+
+func TestBcryptPassword(t *testing.T) {
+	password := RandomString(6)
+
+	hasher, err := NewPasswordHasher(PasswordHasherBcrypt)
+	require.NoError(t, err)
+
+	hashedPassword, err := hasher.Hash(password)
+	require.NoError(t, err)
+
+	err = CheckPassword(password, hashedPassword)
+	require.NoError(t, err)
+
+	err = CheckPassword(RandomString(6), hashedPassword)
+	require.EqualError(t, err, ErrMismatchedPassword.Error())
+
+	require.False(t, hasher.NeedsRehash(hashedPassword))
+	require.True(t, NewArgon2idHasher().NeedsRehash(hashedPassword))
+
+	weakHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.True(t, hasher.NeedsRehash(string(weakHash)))
+}
+
+func TestBcryptKeepsArgon2idHash(t *testing.T) {
+	argon2idHash, err := NewArgon2idHasher().Hash(RandomString(6))
+	require.NoError(t, err)
+
+	// bcrypt is weaker, an argon2id hash is not rehashed into it
+	require.False(t, NewBcryptHasher().NeedsRehash(argon2idHash))
+	require.True(t, NewBcryptHasher().NeedsRehash("plain"))
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	password := RandomString(6)
+
+	weakHasher := NewArgon2idHasher()
+	weakHasher.Time = 1
+	weakHasher.Memory = 1024
+
+	weakHash, err := weakHasher.Hash(password)
+	require.NoError(t, err)
+
+	err = CheckPassword(password, weakHash)
+	require.NoError(t, err)
+
+	hasher := NewArgon2idHasher()
+	require.True(t, hasher.NeedsRehash(weakHash))
+	require.False(t, weakHasher.NeedsRehash(weakHash))
+
+	strongHash, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.False(t, hasher.NeedsRehash(strongHash))
+	require.False(t, weakHasher.NeedsRehash(strongHash))
+
+	require.True(t, hasher.NeedsRehash("$argon2id$broken"))
+	require.EqualError(t, CheckPassword(password, "plain"), ErrUnsupportedPasswordHash.Error())
+
+	_, err = NewPasswordHasher("md5")
+	require.Error(t, err)
+}
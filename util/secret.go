@@ -0,0 +1,24 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// RandomSecret returns n cryptographically random bytes encoded as hex
+func RandomSecret(n int) (string, error) {
+	secret := make([]byte, n)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// HashSecret returns the sha256 hash of a random secret such as an api key, a reset token or a recovery code,
+// they are random enough to not need a slow hash
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
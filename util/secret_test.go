@@ -0,0 +1,23 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomSecret(t *testing.T) {
+	secret1, err := RandomSecret(32)
+	require.NoError(t, err)
+	require.Len(t, secret1, 64)
+
+	secret2, err := RandomSecret(32)
+	require.NoError(t, err)
+	require.NotEqual(t, secret1, secret2)
+
+	hashed := HashSecret(secret1)
+	require.Len(t, hashed, 64)
+	require.NotEqual(t, secret1, hashed)
+	require.Equal(t, hashed, HashSecret(secret1))
+	require.NotEqual(t, hashed, HashSecret(secret2))
+}
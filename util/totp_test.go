@@ -0,0 +1,92 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotpCode(t *testing.T) {
+	// RFC 6238 SHA1 test vectors, truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tc := range testCases {
+		code, err := TotpCode(secret, time.Unix(tc.unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code)
+	}
+
+	_, err := TotpCode("not-base32!", time.Now())
+	require.Error(t, err)
+}
+
+func TestValidateTotp(t *testing.T) {
+	secret, err := GenerateTotpSecret()
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+
+	now := time.Now()
+	code, err := TotpCode(secret, now)
+	require.NoError(t, err)
+
+	require.True(t, ValidateTotp(secret, code, now))
+	require.True(t, ValidateTotp(secret, code, now.Add(30*time.Second)))
+	require.False(t, ValidateTotp(secret, code, now.Add(5*time.Minute)))
+	require.False(t, ValidateTotp(secret, "12345", now))
+
+	other, err := GenerateTotpSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+}
+
+func TestTotpStep(t *testing.T) {
+	secret, err := GenerateTotpSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1234567890, 0)
+	code, err := TotpCode(secret, now)
+	require.NoError(t, err)
+
+	step, valid := TotpStep(secret, code, now)
+	require.True(t, valid)
+	require.Equal(t, now.Unix()/totpPeriod, step)
+
+	// the code checked one period later is still the code of its own step
+	step, valid = TotpStep(secret, code, now.Add(30*time.Second))
+	require.True(t, valid)
+	require.Equal(t, now.Unix()/totpPeriod, step)
+
+	_, valid = TotpStep(secret, "12345", now)
+	require.False(t, valid)
+}
+
+func TestTotpUri(t *testing.T) {
+	uri := TotpUri("pokemart", "ash", "SECRET")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/pokemart:ash?"))
+	require.Contains(t, uri, "secret=SECRET")
+	require.Contains(t, uri, "issuer=pokemart")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	require.NotEqual(t, codes[0], codes[1])
+	require.Len(t, codes[0], 11)
+
+	hashed := HashRecoveryCode(codes[0])
+	require.NotEqual(t, codes[0], hashed)
+	require.Equal(t, hashed, HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+}